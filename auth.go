@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/middleware"
+)
+
+// * requestUser ngambil user yang diset AuthMiddleware, langsung respon 401 kalau gak ada
+func requestUser(w http.ResponseWriter, r *http.Request) (*database.User, bool) {
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Authentication required")
+		return nil, false
+	}
+	return user, true
+}
+
+// * ownerAccessMiddleware cuma ngizinin admin atau pemilik resource di path {param}.
+// * ownerOf ngembaliin userId pemilik resource, ErrNotFound dijawab 404.
+func ownerAccessMiddleware(param, resource string, ownerOf func(ctx context.Context, id string) (string, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := requestUser(w, r)
+			if !ok {
+				return
+			}
+			if user.Role == enums.RoleAdmin {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			id := r.PathValue(param)
+			ownerID, err := ownerOf(r.Context(), id)
+			if errors.Is(err, database.ErrNotFound) {
+				respondWithError(w, http.StatusNotFound, fmt.Sprintf("%s %s not found", resource, id))
+				return
+			}
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get %s %s: %v", strings.ToLower(resource), id, err))
+				return
+			}
+			if ownerID != user.ID {
+				middleware.RespondForbidden(w, fmt.Sprintf("You can only access your own %ss", strings.ToLower(resource)), enums.RoleAdmin)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (apiCfg *apiConfig) orderAccessMiddleware(next http.Handler) http.Handler {
+	return ownerAccessMiddleware("orderID", "Order", func(ctx context.Context, id string) (string, error) {
+		order, err := apiCfg.DB.Orders.GetOrderByID(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return order.UserID, nil
+	})(next)
+}
+
+func (apiCfg *apiConfig) reservationAccessMiddleware(next http.Handler) http.Handler {
+	return ownerAccessMiddleware("reservationID", "Reservation", func(ctx context.Context, id string) (string, error) {
+		reservation, err := apiCfg.DB.TableReservations.GetTableReservationByID(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return reservation.UserID, nil
+	})(next)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// * newTestAccessRouter: order o1 dan reservasi r1 milik u1, handler-nya cuma balas 200
+func newTestAccessRouter(t *testing.T) http.Handler {
+	t.Helper()
+	db := database.NewMemoryDB()
+	store := db.Store()
+	ctx := context.Background()
+
+	table, err := store.Tables.CreateRestaurantTable(ctx, database.CreateRestaurantTableRequest{TableNumber: "T1", Capacity: 4, IsAvailable: true})
+	if err != nil {
+		t.Fatalf("CreateRestaurantTable: %v", err)
+	}
+	err = store.Orders.CreateOrder(ctx, database.CreateOrderRequest{
+		Order: database.Order{ID: "o1", UserID: "u1", Status: enums.OrderStatusPending, PaymentStatus: enums.PaymentStatusPending},
+		TableReservation: &database.TableReservation{
+			ID:              "r1",
+			UserID:          "u1",
+			TableID:         table.ID,
+			OrderID:         "o1",
+			ReservationTime: time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC),
+			Status:          enums.StatusReserved,
+			PartySize:       2,
+		},
+		SeatingDuration: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	apiCfg := &apiConfig{DB: store}
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	r := chi.NewRouter()
+	r.With(apiCfg.orderAccessMiddleware).Get("/orders/{orderID}", ok)
+	r.With(apiCfg.reservationAccessMiddleware).Get("/reservations/{reservationID}", ok)
+	return r
+}
+
+func TestOwnerAccessMiddleware(t *testing.T) {
+	admin := &database.User{ID: "a1", Role: enums.RoleAdmin}
+	owner := &database.User{ID: "u1", Role: enums.RoleUser}
+	other := &database.User{ID: "u2", Role: enums.RoleUser}
+
+	tests := []struct {
+		name       string
+		path       string
+		user       *database.User
+		wantStatus int
+	}{
+		{name: "order admin", path: "/orders/o1", user: admin, wantStatus: http.StatusOK},
+		{name: "order owner", path: "/orders/o1", user: owner, wantStatus: http.StatusOK},
+		{name: "order other user", path: "/orders/o1", user: other, wantStatus: http.StatusForbidden},
+		{name: "order missing", path: "/orders/missing", user: other, wantStatus: http.StatusNotFound},
+		{name: "order no user", path: "/orders/o1", wantStatus: http.StatusUnauthorized},
+		{name: "reservation admin", path: "/reservations/r1", user: admin, wantStatus: http.StatusOK},
+		{name: "reservation owner", path: "/reservations/r1", user: owner, wantStatus: http.StatusOK},
+		{name: "reservation other user", path: "/reservations/r1", user: other, wantStatus: http.StatusForbidden},
+		{name: "reservation missing", path: "/reservations/missing", user: other, wantStatus: http.StatusNotFound},
+		{name: "reservation no user", path: "/reservations/r1", wantStatus: http.StatusUnauthorized},
+	}
+
+	router := newTestAccessRouter(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.user != nil {
+				req = req.WithContext(middleware.ContextWithUser(req.Context(), tt.user))
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusForbidden {
+				return
+			}
+
+			var body struct {
+				Code          string       `json:"code"`
+				RequiredRoles []enums.Role `json:"requiredRoles"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid error body %q: %v", rec.Body.String(), err)
+			}
+			if body.Code != "forbidden" || len(body.RequiredRoles) != 1 || body.RequiredRoles[0] != enums.RoleAdmin {
+				t.Errorf("body = %+v, want code forbidden and requiredRoles [admin]", body)
+			}
+		})
+	}
+}
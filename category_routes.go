@@ -0,0 +1,27 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func categoryRoutes(apiCfg *apiConfig) http.Handler {
+	r := chi.NewRouter()
+
+	// * Semua user yang login
+	r.Get("/", apiCfg.handlerGetCategories)
+	r.Get("/{categoryID}", apiCfg.handlerGetCategoryByID)
+
+	// * Admin only
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireRole(enums.RoleAdmin))
+		r.Post("/", apiCfg.handlerCreateCategory)
+		r.Put("/{categoryID}", apiCfg.handlerUpdateCategory)
+		r.Delete("/{categoryID}", apiCfg.handlerDeleteCategory)
+	})
+
+	return r
+}
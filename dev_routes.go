@@ -0,0 +1,22 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// * devRoutes cuma di-mount kalau PAYMENT_GATEWAY=fake, buat local development
+func devRoutes(apiCfg *apiConfig) http.Handler {
+	r := chi.NewRouter()
+
+	// * Admin only
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireRole(enums.RoleAdmin))
+		r.Post("/orders/{orderID}/simulate-payment", apiCfg.handlerSimulatePayment)
+	})
+
+	return r
+}
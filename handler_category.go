@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+)
+
+func (apiCfg *apiConfig) handlerCreateCategory(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Name        string  `json:"name"`
+		Description *string `json:"description,omitempty"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+	if strings.TrimSpace(params.Name) == "" {
+		respondWithError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	category, err := apiCfg.DB.Categories.CreateCategory(r.Context(), database.CreateCategoryRequest{
+		Name:        params.Name,
+		Description: params.Description,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't create category: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, dbCategoryToCategory(*category))
+}
+
+func (apiCfg *apiConfig) handlerGetCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := apiCfg.DB.Categories.GetAllCategories(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get categories: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbCategoriesToCategories(categories))
+}
+
+func (apiCfg *apiConfig) handlerGetCategoryByID(w http.ResponseWriter, r *http.Request) {
+	categoryID := r.PathValue("categoryID")
+
+	category, err := apiCfg.DB.Categories.GetCategoryByID(r.Context(), categoryID)
+	if errors.Is(err, database.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Category %s not found", categoryID))
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get category %s: %v", categoryID, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbCategoryToCategory(*category))
+}
+
+func (apiCfg *apiConfig) handlerUpdateCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID := r.PathValue("categoryID")
+
+	type parameters struct {
+		Name        *string `json:"name,omitempty"`
+		Description *string `json:"description,omitempty"` // "" buat ngehapus deskripsi
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+	if params.Name != nil && strings.TrimSpace(*params.Name) == "" {
+		respondWithError(w, http.StatusBadRequest, "name cannot be empty")
+		return
+	}
+
+	category, err := apiCfg.DB.Categories.UpdateCategory(r.Context(), categoryID, database.UpdateCategoryRequest{
+		Name:        params.Name,
+		Description: params.Description,
+	})
+	if errors.Is(err, database.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Category %s not found", categoryID))
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't update category %s: %v", categoryID, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbCategoryToCategory(*category))
+}
+
+func (apiCfg *apiConfig) handlerDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID := r.PathValue("categoryID")
+
+	err := apiCfg.DB.Categories.DeleteCategory(r.Context(), categoryID)
+	if errors.Is(err, database.ErrCategoryInUse) {
+		respondWithError(w, http.StatusConflict, fmt.Sprintf("Category %s still has menu items, move or delete them first", categoryID))
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't delete category %s: %v", categoryID, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
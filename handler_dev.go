@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+	"github.com/Rizz404/midtrans-handler/internal/paymentstatus"
+)
+
+// * handlerSimulatePayment cuma ada kalau PAYMENT_GATEWAY=fake: ganti status transaksi di fake gateway
+// * lalu kirim webhook-nya ke server ini, persis kayak customer bayar di Midtrans
+func (apiCfg *apiConfig) handlerSimulatePayment(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+
+	fakeGateway, ok := apiCfg.PaymentGateway.(*gateway.FakeGateway)
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Payment simulation is only available with the fake payment gateway")
+		return
+	}
+
+	type parameters struct {
+		TransactionStatus string `json:"transactionStatus"` // Default settlement
+		FraudStatus       string `json:"fraudStatus"`       // Default accept
+	}
+
+	params := parameters{
+		TransactionStatus: paymentstatus.TransactionSettlement,
+		FraudStatus:       paymentstatus.FraudAccept,
+	}
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&params)
+	if err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+	if mapped := paymentstatus.FromMidtrans(params.TransactionStatus, params.FraudStatus); mapped.Outcome == paymentstatus.OutcomeUnknown {
+		respondWithError(w, http.StatusBadRequest, mapped.Reason)
+		return
+	}
+
+	if err := fakeGateway.SetTransactionStatus(orderID, params.TransactionStatus, params.FraudStatus); err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err := fakeGateway.Notify(r.Context(), orderID); err != nil {
+		respondWithError(w, http.StatusBadGateway, fmt.Sprintf("Couldn't deliver notification: %v", err))
+		return
+	}
+
+	order, err := apiCfg.DB.Orders.GetOrderByID(r.Context(), orderID)
+	if errors.Is(err, database.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Order %s not found", orderID))
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get order %s: %v", orderID, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbOrderToOrder(*order))
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+)
+
+// * validateMenuItemFields dipakai create dan update, field nil dilewati
+func validateMenuItemFields(name *string, price *float64, imageUrl *string) string {
+	if name != nil && strings.TrimSpace(*name) == "" {
+		return "name cannot be empty"
+	}
+	// * Midtrans cuma nerima rupiah bulat
+	if price != nil && (*price <= 0 || *price != math.Trunc(*price)) {
+		return "price must be a positive whole number"
+	}
+	if imageUrl != nil && *imageUrl != "" {
+		parsed, err := url.ParseRequestURI(*imageUrl)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return "imageUrl must be an absolute http(s) URL"
+		}
+	}
+	return ""
+}
+
+func respondWithMenuItemError(w http.ResponseWriter, menuItemID string, err error) {
+	switch {
+	case errors.Is(err, database.ErrCategoryNotFound):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrNotFound):
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Menu item %s not found", menuItemID))
+	default:
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't save menu item: %v", err))
+	}
+}
+
+func (apiCfg *apiConfig) handlerCreateMenuItem(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Name        string  `json:"name"`
+		Price       float64 `json:"price"`
+		ImageUrl    *string `json:"imageUrl,omitempty"`
+		CategoryId  *string `json:"categoryId,omitempty"`
+		IsAvailable *bool   `json:"isAvailable,omitempty"` // Default true
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+	if params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if reason := validateMenuItemFields(&params.Name, &params.Price, params.ImageUrl); reason != "" {
+		respondWithError(w, http.StatusBadRequest, reason)
+		return
+	}
+	if params.ImageUrl != nil && *params.ImageUrl == "" {
+		params.ImageUrl = nil
+	}
+	if params.CategoryId != nil && *params.CategoryId == "" {
+		params.CategoryId = nil
+	}
+
+	isAvailable := true
+	if params.IsAvailable != nil {
+		isAvailable = *params.IsAvailable
+	}
+
+	menuItem, err := apiCfg.DB.MenuItems.CreateMenuItem(r.Context(), database.CreateMenuItemRequest{
+		Name:        params.Name,
+		Price:       params.Price,
+		ImageUrl:    params.ImageUrl,
+		CategoryId:  params.CategoryId,
+		IsAvailable: isAvailable,
+	})
+	if err != nil {
+		respondWithMenuItemError(w, "", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, dbDenormalizedMenuItemToDenormalizedMenuItem(*menuItem))
+}
+
+func (apiCfg *apiConfig) handlerGetMenuItems(w http.ResponseWriter, r *http.Request) {
+	// * ?categoryId= dan ?isAvailable=true|false buat layar menu
+	query := r.URL.Query()
+	request := database.ListMenuItemsRequest{
+		CategoryID: query.Get("categoryId"),
+	}
+	if value := query.Get("isAvailable"); value != "" {
+		isAvailable, err := strconv.ParseBool(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid isAvailable %q, must be true or false", value))
+			return
+		}
+		request.IsAvailable = &isAvailable
+	}
+
+	menuItems, err := apiCfg.DB.MenuItems.ListMenuItems(r.Context(), request)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get menu items: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbDenormalizedMenuItemsToDenormalizedMenuItems(menuItems))
+}
+
+func (apiCfg *apiConfig) handlerGetMenuItemByID(w http.ResponseWriter, r *http.Request) {
+	menuItemID := r.PathValue("menuItemID")
+
+	menuItem, err := apiCfg.DB.MenuItems.GetMenuItemByID(r.Context(), menuItemID)
+	if errors.Is(err, database.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Menu item %s not found", menuItemID))
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get menu item %s: %v", menuItemID, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbDenormalizedMenuItemToDenormalizedMenuItem(*menuItem))
+}
+
+func (apiCfg *apiConfig) handlerUpdateMenuItem(w http.ResponseWriter, r *http.Request) {
+	menuItemID := r.PathValue("menuItemID")
+
+	type parameters struct {
+		Name        *string  `json:"name,omitempty"`
+		Price       *float64 `json:"price,omitempty"`
+		ImageUrl    *string  `json:"imageUrl,omitempty"`   // "" buat ngehapus gambar
+		CategoryId  *string  `json:"categoryId,omitempty"` // "" buat ngeluarin dari kategori
+		IsAvailable *bool    `json:"isAvailable,omitempty"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+	if reason := validateMenuItemFields(params.Name, params.Price, params.ImageUrl); reason != "" {
+		respondWithError(w, http.StatusBadRequest, reason)
+		return
+	}
+
+	menuItem, err := apiCfg.DB.MenuItems.UpdateMenuItem(r.Context(), menuItemID, database.UpdateMenuItemRequest{
+		Name:        params.Name,
+		Price:       params.Price,
+		ImageUrl:    params.ImageUrl,
+		CategoryId:  params.CategoryId,
+		IsAvailable: params.IsAvailable,
+	})
+	if err != nil {
+		respondWithMenuItemError(w, menuItemID, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbDenormalizedMenuItemToDenormalizedMenuItem(*menuItem))
+}
+
+// * handlerSetMenuItemAvailability buat toggle cepat dari dapur waktu menu habis / ada lagi
+func (apiCfg *apiConfig) handlerSetMenuItemAvailability(w http.ResponseWriter, r *http.Request) {
+	menuItemID := r.PathValue("menuItemID")
+
+	type parameters struct {
+		IsAvailable *bool `json:"isAvailable"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+	if params.IsAvailable == nil {
+		respondWithError(w, http.StatusBadRequest, "isAvailable is required")
+		return
+	}
+
+	menuItem, err := apiCfg.DB.MenuItems.UpdateMenuItem(r.Context(), menuItemID, database.UpdateMenuItemRequest{
+		IsAvailable: params.IsAvailable,
+	})
+	if err != nil {
+		respondWithMenuItemError(w, menuItemID, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbDenormalizedMenuItemToDenormalizedMenuItem(*menuItem))
+}
+
+func (apiCfg *apiConfig) handlerDeleteMenuItem(w http.ResponseWriter, r *http.Request) {
+	menuItemID := r.PathValue("menuItemID")
+
+	err := apiCfg.DB.MenuItems.DeleteMenuItem(r.Context(), menuItemID)
+	if errors.Is(err, database.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Menu item %s not found", menuItemID))
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't delete menu item %s: %v", menuItemID, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
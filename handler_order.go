@@ -2,23 +2,33 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/Rizz404/midtrans-handler/internal/database"
 	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/middleware"
 )
 
 func (apiCfg *apiConfig) handlerCreateOrder(w http.ResponseWriter, r *http.Request) {
+	user, ok := requestUser(w, r)
+	if !ok {
+		return
+	}
+
 	type parameters struct {
-		UserID              string                                  `json:"userId"`
 		PaymentMethodID     string                                  `json:"paymentMethodId"`
 		OrderType           enums.OrderType                         `json:"orderType"`
 		EstimatedReadyTime  *time.Time                              `json:"estimatedReadyTime,omitempty"`
 		SpecialInstructions *string                                 `json:"specialInstructions,omitempty"`
 		OrderItems          []database.OrderItem                    `json:"orderItems"`
 		TableReservation    *database.CreateTableReservationRequest `json:"tableReservation,omitempty"`
+		CardToken           *string                                 `json:"cardToken,omitempty"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -31,19 +41,39 @@ func (apiCfg *apiConfig) handlerCreateOrder(w http.ResponseWriter, r *http.Reque
 
 	finalOrder, err := database.CreateOrderWithPayment(
 		r.Context(),
-		apiCfg.Firestore,
-		apiCfg.MidtransCore,
+		apiCfg.DB,
+		apiCfg.PaymentGateway,
 		database.CreateOrderWithPaymentRequest{
-			UserID:              params.UserID,
+			UserID:              user.ID, // * Dari token, userId di body gak dipercaya lagi
 			PaymentMethodID:     params.PaymentMethodID,
 			OrderType:           params.OrderType,
 			EstimatedReadyTime:  params.EstimatedReadyTime,
 			SpecialInstructions: params.SpecialInstructions,
 			OrderItems:          params.OrderItems,
 			TableReservation:    params.TableReservation,
+			CardToken:           params.CardToken,
+			SeatingDuration:     apiCfg.ReservationSeatingDuration,
+			OpeningHours:        &apiCfg.OpeningHours,
 		},
 	)
 
+	// * Sudah ke-charge, jadi dibalas 202 (bukan 5xx) biar Idempotency-Key gak dilepas dan client gak bikin order baru.
+	// * Replay 202 baca ulang order-nya, jadi retry dapet instruksi pembayaran setelah outbox worker selesai.
+	if errors.Is(err, database.ErrOrderFinalizePending) && finalOrder != nil {
+		log.Printf("ORDER: %v", err)
+		respondWithJSON(w, http.StatusAccepted, dbOrderToOrder(*finalOrder))
+		return
+	}
+
+	var conflictErr *database.ReservationConflictError
+	if errors.As(err, &conflictErr) {
+		respondWithReservationConflict(w, conflictErr)
+		return
+	}
+	if errors.Is(err, database.ErrInvalidOrder) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -55,7 +85,7 @@ func (apiCfg *apiConfig) handlerCreateOrder(w http.ResponseWriter, r *http.Reque
 func (apiCfg *apiConfig) handlerGetOrderByID(w http.ResponseWriter, r *http.Request) {
 	orderID := r.PathValue("orderID")
 
-	order, err := database.GetOrderByID(r.Context(), apiCfg.Firestore, orderID)
+	order, err := apiCfg.DB.Orders.GetOrderByID(r.Context(), orderID)
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Couldn't get order with ID %s: %v", orderID, err))
 		return
@@ -64,22 +94,442 @@ func (apiCfg *apiConfig) handlerGetOrderByID(w http.ResponseWriter, r *http.Requ
 	respondWithJSON(w, http.StatusOK, dbOrderToOrder(*order))
 }
 
+func (apiCfg *apiConfig) handlerGetOrders(w http.ResponseWriter, r *http.Request) {
+	user, ok := requestUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	request := database.ListOrdersRequest{
+		UserID:          query.Get("userId"),
+		Status:          enums.OrderStatus(query.Get("status")),
+		PaymentStatus:   enums.PaymentStatus(query.Get("paymentStatus")),
+		OrderType:       enums.OrderType(query.Get("orderType")),
+		PaymentMethodID: query.Get("paymentMethodId"),
+		Cursor:          query.Get("cursor"),
+		Descending:      true, // * Default order terbaru duluan
+	}
+
+	// * Customer cuma boleh lihat order miliknya sendiri
+	if user.Role != enums.RoleAdmin {
+		if request.UserID != "" && request.UserID != user.ID {
+			middleware.RespondForbidden(w, "You can only list your own orders", enums.RoleAdmin)
+			return
+		}
+		request.UserID = user.ID
+	}
+
+	switch sort := query.Get("sort"); sort {
+	case "", "desc":
+	case "asc":
+		request.Descending = false
+	default:
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid sort %q, must be asc or desc", sort))
+		return
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > database.MaxListOrdersLimit {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", database.MaxListOrdersLimit))
+			return
+		}
+		request.Limit = limit
+	}
+
+	for param, target := range map[string]**time.Time{
+		"orderDateFrom": &request.OrderDateFrom,
+		"orderDateTo":   &request.OrderDateTo,
+	} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s %q, must be RFC3339", param, value))
+			return
+		}
+		*target = &parsed
+	}
+
+	result, err := apiCfg.DB.Orders.ListOrders(r.Context(), request)
+	if errors.Is(err, database.ErrInvalidCursor) || errors.Is(err, database.ErrInvalidOrder) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't list orders: %v", err))
+		return
+	}
+
+	type response struct {
+		Orders     []Order `json:"orders"`
+		NextCursor string  `json:"nextCursor,omitempty"`
+	}
+
+	respondWithJSON(w, http.StatusOK, response{
+		Orders:     dbOrdersToOrders(result.Orders),
+		NextCursor: result.NextCursor,
+	})
+}
+
 func (apiCfg *apiConfig) handlerUpdateOrder(w http.ResponseWriter, r *http.Request) {
 	orderID := r.PathValue("orderID")
 
+	type parameters struct {
+		OrderStatus   *enums.OrderStatus   `json:"orderStatus,omitempty"`
+		PaymentStatus *enums.PaymentStatus `json:"paymentStatus,omitempty"`
+		PaymentProof  *string              `json:"paymentProof,omitempty"` // Ditolak, pakai endpoint payment-proof
+	}
+
 	decoder := json.NewDecoder(r.Body)
-	params := database.UpdateOrderRequest{}
+	params := parameters{}
 	err := decoder.Decode(&params)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
 		return
 	}
+	if params.PaymentProof != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("paymentProof can't be changed here, use POST /v1/orders/%s/payment-proof", orderID))
+		return
+	}
+	if params.OrderStatus == nil && params.PaymentStatus == nil {
+		respondWithError(w, http.StatusBadRequest, "orderStatus or paymentStatus is required")
+		return
+	}
+
+	// * Cancel harus lewat alur cancel biar transaksi di Midtrans ikut dibatalkan
+	if params.OrderStatus != nil && *params.OrderStatus == enums.OrderStatusCancelled {
+		if params.PaymentStatus != nil {
+			respondWithError(w, http.StatusBadRequest, "paymentStatus can't be set when cancelling, it is set by the cancel flow")
+			return
+		}
+		apiCfg.cancelOrderAsAdmin(w, r, orderID)
+		return
+	}
 
-	updatedOrder, err := database.UpdateOrder(r.Context(), apiCfg.Firestore, orderID, params)
+	updatedOrder, err := apiCfg.DB.Orders.UpdateOrder(r.Context(), orderID, database.UpdateOrderRequest{
+		OrderStatus:     params.OrderStatus,
+		PaymentStatus:   params.PaymentStatus,
+		Precondition:    requireAdminUpdatableOrder(params.OrderStatus, params.PaymentStatus),
+		SyncReservation: true,
+	})
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't update order %s: %v", orderID, err))
+		respondWithUpdateOrderError(w, orderID, err)
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, dbOrderToOrder(*updatedOrder))
 }
+
+// * cancelOrderAsAdmin: PATCH orderStatus=cancelled diterusin ke CancelOrder.
+// * Order yang sudah dibayar ditolak di sana (409), harus di-refund dulu.
+func (apiCfg *apiConfig) cancelOrderAsAdmin(w http.ResponseWriter, r *http.Request, orderID string) {
+	order, err := apiCfg.DB.Orders.GetOrderByID(r.Context(), orderID)
+	if err != nil {
+		respondWithUpdateOrderError(w, orderID, err)
+		return
+	}
+	if err := rejectCreatingOrder(*order); err != nil {
+		respondWithUpdateOrderError(w, orderID, err)
+		return
+	}
+
+	cancelledOrder, err := database.CancelOrder(r.Context(), apiCfg.DB, apiCfg.PaymentGateway, orderID)
+	if err != nil {
+		respondWithUpdateOrderError(w, orderID, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbOrderToOrder(*cancelledOrder))
+}
+
+var (
+	errNotManualPayment   = errors.New("order does not use a manual payment method")
+	errPaymentNotPending  = errors.New("order payment is no longer pending")
+	errOrderStillCreating = errors.New("order is still being created, try again later")
+	errOrderNotPaid       = errors.New("order is not paid")
+)
+
+// * Status dapur: order cuma boleh masuk sini kalau sudah dibayar (atau bayar manual di kasir)
+var kitchenOrderStatuses = []enums.OrderStatus{
+	enums.OrderStatusConfirmed,
+	enums.OrderStatusPreparing,
+	enums.OrderStatusReady,
+	enums.OrderStatusCompleted,
+}
+
+// * rejectCreatingOrder: order creating masih diurus outbox worker (charge Midtrans), jangan diubah manual
+func rejectCreatingOrder(current database.Order) error {
+	if current.Status == enums.OrderStatusCreating {
+		return errOrderStillCreating
+	}
+	return nil
+}
+
+// * requireAdminUpdatableOrder dipakai sebagai Precondition PATCH order: order creating ditolak,
+// * dan status dapur butuh pembayaran success (status setelah request ini) kecuali pembayaran manual
+func requireAdminUpdatableOrder(orderStatus *enums.OrderStatus, paymentStatus *enums.PaymentStatus) func(current database.Order) error {
+	return func(current database.Order) error {
+		if err := rejectCreatingOrder(current); err != nil {
+			return err
+		}
+		if orderStatus == nil || !slices.Contains(kitchenOrderStatuses, *orderStatus) || current.ManualPayment {
+			return nil
+		}
+
+		effectivePaymentStatus := current.PaymentStatus
+		if paymentStatus != nil {
+			effectivePaymentStatus = *paymentStatus
+		}
+		if effectivePaymentStatus != enums.PaymentStatusSuccess {
+			return fmt.Errorf("%w: payment is %s, order can't move to %s", errOrderNotPaid, effectivePaymentStatus, *orderStatus)
+		}
+		return nil
+	}
+}
+
+// * requireManualPendingPayment dipakai sebagai Precondition buat endpoint pembayaran manual
+func requireManualPendingPayment(current database.Order) error {
+	if !current.ManualPayment {
+		return errNotManualPayment
+	}
+	if current.PaymentStatus != enums.PaymentStatusPending {
+		return errPaymentNotPending
+	}
+	return nil
+}
+
+func (apiCfg *apiConfig) handlerUploadPaymentProof(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+
+	type parameters struct {
+		PaymentProof string `json:"paymentProof"` // URL / path file yang sudah di-upload client
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+	if params.PaymentProof == "" {
+		respondWithError(w, http.StatusBadRequest, "paymentProof is required")
+		return
+	}
+
+	updatedOrder, err := apiCfg.DB.Orders.UpdateOrder(r.Context(), orderID, database.UpdateOrderRequest{
+		PaymentProof: &params.PaymentProof,
+		Precondition: requireManualPendingPayment,
+	})
+	if err != nil {
+		respondWithUpdateOrderError(w, orderID, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbOrderToOrder(*updatedOrder))
+}
+
+func (apiCfg *apiConfig) handlerReviewManualPayment(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+
+	type parameters struct {
+		Decision string `json:"decision"` // confirm atau reject
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+
+	var paymentStatus enums.PaymentStatus
+	var orderStatus enums.OrderStatus
+	switch params.Decision {
+	case "confirm":
+		paymentStatus = enums.PaymentStatusSuccess
+		orderStatus = enums.OrderStatusConfirmed
+	case "reject":
+		paymentStatus = enums.PaymentStatusDeny
+		orderStatus = enums.OrderStatusCancelled
+	default:
+		respondWithError(w, http.StatusBadRequest, `decision must be "confirm" or "reject"`)
+		return
+	}
+
+	updatedOrder, err := apiCfg.DB.Orders.UpdateOrder(r.Context(), orderID, database.UpdateOrderRequest{
+		OrderStatus:     &orderStatus,
+		PaymentStatus:   &paymentStatus,
+		Precondition:    requireManualPendingPayment,
+		SyncReservation: true,
+	})
+	if err != nil {
+		respondWithUpdateOrderError(w, orderID, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbOrderToOrder(*updatedOrder))
+}
+
+func (apiCfg *apiConfig) handlerCancelOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+
+	cancelledOrder, err := database.CancelOrder(r.Context(), apiCfg.DB, apiCfg.PaymentGateway, orderID)
+	if err != nil {
+		respondWithUpdateOrderError(w, orderID, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbOrderToOrder(*cancelledOrder))
+}
+
+func (apiCfg *apiConfig) handlerCreateRefund(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+
+	user, ok := requestUser(w, r)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		Amount *float64 `json:"amount"` // Kosong = refund semua sisa
+		Reason string   `json:"reason"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+	if params.Reason == "" {
+		respondWithError(w, http.StatusBadRequest, "reason is required")
+		return
+	}
+
+	refundedOrder, refund, err := database.RefundOrder(r.Context(), apiCfg.DB, apiCfg.PaymentGateway, orderID, database.RefundOrderRequest{
+		Amount:  params.Amount,
+		Reason:  params.Reason,
+		ActorID: user.ID,
+	})
+	if err != nil {
+		respondWithUpdateOrderError(w, orderID, err)
+		return
+	}
+
+	type response struct {
+		Order  Order  `json:"order"`
+		Refund Refund `json:"refund"`
+	}
+	respondWithJSON(w, http.StatusCreated, response{
+		Order:  dbOrderToOrder(*refundedOrder),
+		Refund: dbRefundToRefund(*refund),
+	})
+}
+
+func (apiCfg *apiConfig) handlerGetRefunds(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+
+	if _, err := apiCfg.DB.Orders.GetOrderByID(r.Context(), orderID); err != nil {
+		respondWithUpdateOrderError(w, orderID, err)
+		return
+	}
+
+	refunds, err := apiCfg.DB.Refunds.GetRefundsByOrderID(r.Context(), orderID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get refunds for order %s: %v", orderID, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbRefundsToRefunds(refunds))
+}
+
+func (apiCfg *apiConfig) handlerGetPaymentEvents(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+
+	if _, err := apiCfg.DB.Orders.GetOrderByID(r.Context(), orderID); err != nil {
+		respondWithUpdateOrderError(w, orderID, err)
+		return
+	}
+
+	events, err := apiCfg.DB.PaymentEvents.GetPaymentEventsByOrderID(r.Context(), orderID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get payment events for order %s: %v", orderID, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbPaymentEventsToPaymentEvents(events))
+}
+
+func (apiCfg *apiConfig) handlerSyncOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+
+	result, err := database.SyncOrderPaymentStatus(r.Context(), apiCfg.DB, apiCfg.PaymentGateway, orderID)
+	if err != nil {
+		respondWithUpdateOrderError(w, orderID, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func (apiCfg *apiConfig) handlerReconcileOrders(w http.ResponseWriter, r *http.Request) {
+	limit := reconciliationBatchSize
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	report, err := database.ReconcileOrders(r.Context(), apiCfg.DB, apiCfg.PaymentGateway, limit)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't reconcile orders: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+func respondWithUpdateOrderError(w http.ResponseWriter, orderID string, err error) {
+	var transitionErr *database.InvalidTransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		respondWithInvalidTransition(w, transitionErr)
+	case errors.Is(err, database.ErrNotFound):
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Order %s not found", orderID))
+	case errors.Is(err, database.ErrInvalidRefund):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, errNotManualPayment), errors.Is(err, errPaymentNotPending), errors.Is(err, errOrderStillCreating), errors.Is(err, errOrderNotPaid),
+		errors.Is(err, database.ErrOrderNotCancellable), errors.Is(err, database.ErrOrderNotRefundable):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, database.ErrPaymentGateway):
+		respondWithError(w, http.StatusBadGateway, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't update order %s: %v", orderID, err))
+	}
+}
+
+func respondWithInvalidTransition(w http.ResponseWriter, transitionErr *database.InvalidTransitionError) {
+	type conflictResponse struct {
+		Error              string   `json:"error"`
+		Field              string   `json:"field"`
+		CurrentStatus      string   `json:"currentStatus"`
+		AllowedTransitions []string `json:"allowedTransitions"`
+	}
+
+	respondWithJSON(w, http.StatusConflict, conflictResponse{
+		Error:              transitionErr.Error(),
+		Field:              transitionErr.Field,
+		CurrentStatus:      transitionErr.From,
+		AllowedTransitions: transitionErr.Allowed,
+	})
+}
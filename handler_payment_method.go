@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/Rizz404/midtrans-handler/internal/database"
 	"github.com/Rizz404/midtrans-handler/internal/enums"
@@ -31,7 +32,7 @@ func (apiCfg *apiConfig) handlerCreatePaymentMethod(w http.ResponseWriter, r *ht
 		return
 	}
 
-	paymentMethod, err := database.CreatePaymentMethod(r.Context(), apiCfg.Firestore, database.CreatePaymentMethodRequest{
+	paymentMethod, err := apiCfg.DB.PaymentMethods.CreatePaymentMethod(r.Context(), database.CreatePaymentMethodRequest{
 		Name:                      params.Name,
 		Description:               params.Description,
 		Logo:                      params.Logo,
@@ -66,7 +67,7 @@ func (apiCfg *apiConfig) handlerBulkCreatePaymentMethods(w http.ResponseWriter,
 		return
 	}
 
-	paymentMethods, err := database.BulkCreatePaymentMethods(r.Context(), apiCfg.Firestore, params)
+	paymentMethods, err := apiCfg.DB.PaymentMethods.BulkCreatePaymentMethods(r.Context(), params)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't bulk create payment methods: %v", err))
 		return
@@ -76,19 +77,53 @@ func (apiCfg *apiConfig) handlerBulkCreatePaymentMethods(w http.ResponseWriter,
 }
 
 func (apiCfg *apiConfig) handlerGetAllPaymentMethods(w http.ResponseWriter, r *http.Request) {
-	paymentMethods, err := database.GetAllPaymentMethods(r.Context(), apiCfg.Firestore)
+	paymentMethods, err := apiCfg.DB.PaymentMethods.GetAllPaymentMethods(r.Context())
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get payment methods: %v", err))
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, dbPaymentMethodsToPaymentMethods(paymentMethods))
+	// * ?amount= dipakai checkout buat nampilin cuma method yang bisa dipakai buat total basket
+	amountParam := r.URL.Query().Get("amount")
+	if amountParam == "" {
+		respondWithJSON(w, http.StatusOK, dbPaymentMethodsToPaymentMethods(paymentMethods))
+		return
+	}
+
+	amount, err := strconv.ParseFloat(amountParam, 64)
+	if err != nil || amount < 0 {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid amount %q", amountParam))
+		return
+	}
+
+	type unavailablePaymentMethod struct {
+		PaymentMethod PaymentMethod `json:"paymentMethod"`
+		Reason        string        `json:"reason"`
+	}
+	type response struct {
+		Available   []PaymentMethod            `json:"available"`
+		Unavailable []unavailablePaymentMethod `json:"unavailable"`
+	}
+
+	resp := response{Available: []PaymentMethod{}, Unavailable: []unavailablePaymentMethod{}}
+	for _, pm := range paymentMethods {
+		if reason := pm.UnavailableReason(amount); reason != "" {
+			resp.Unavailable = append(resp.Unavailable, unavailablePaymentMethod{
+				PaymentMethod: dbPaymentMethodToPaymentMethod(pm),
+				Reason:        reason,
+			})
+			continue
+		}
+		resp.Available = append(resp.Available, dbPaymentMethodToPaymentMethod(pm))
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
 }
 
 func (apiCfg *apiConfig) handlerGetPaymentMethodByID(w http.ResponseWriter, r *http.Request) {
 	paymentMethodID := r.PathValue("paymentMethodID")
 
-	paymentMethod, err := database.GetPaymentMethodByID(r.Context(), apiCfg.Firestore, paymentMethodID)
+	paymentMethod, err := apiCfg.DB.PaymentMethods.GetPaymentMethodByID(r.Context(), paymentMethodID)
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Couldn't get payment method with ID %s: %v", paymentMethodID, err))
 		return
@@ -108,7 +143,7 @@ func (apiCfg *apiConfig) handlerUpdatePaymentMethod(w http.ResponseWriter, r *ht
 		return
 	}
 
-	updatedPaymentMethod, err := database.UpdatePaymentMethod(r.Context(), apiCfg.Firestore, paymentMethodID, params)
+	updatedPaymentMethod, err := apiCfg.DB.PaymentMethods.UpdatePaymentMethod(r.Context(), paymentMethodID, params)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't update payment method %s: %v", paymentMethodID, err))
 		return
@@ -120,7 +155,7 @@ func (apiCfg *apiConfig) handlerUpdatePaymentMethod(w http.ResponseWriter, r *ht
 func (apiCfg *apiConfig) handlerDeletePaymentMethod(w http.ResponseWriter, r *http.Request) {
 	paymentMethodID := r.PathValue("paymentMethodID")
 
-	err := database.DeletePaymentMethod(r.Context(), apiCfg.Firestore, paymentMethodID)
+	err := apiCfg.DB.PaymentMethods.DeletePaymentMethod(r.Context(), paymentMethodID)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't delete payment method %s: %v", paymentMethodID, err))
 		return
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+	"github.com/Rizz404/midtrans-handler/middleware"
+)
+
+func (apiCfg *apiConfig) handlerGetReservations(w http.ResponseWriter, r *http.Request) {
+	user, ok := requestUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	request := database.ListTableReservationsRequest{
+		UserID:  query.Get("userId"),
+		TableID: query.Get("tableId"),
+		Status:  enums.ReservationStatus(query.Get("status")),
+	}
+
+	// * Customer cuma boleh lihat reservasi miliknya sendiri
+	if user.Role != enums.RoleAdmin {
+		if request.UserID != "" && request.UserID != user.ID {
+			middleware.RespondForbidden(w, "You can only list your own reservations", enums.RoleAdmin)
+			return
+		}
+		request.UserID = user.ID
+	}
+
+	// * ?date=2006-01-02 dihitung dalam WIB (jam operasional restoran)
+	if value := query.Get("date"); value != "" {
+		from, err := time.ParseInLocation(time.DateOnly, value, gateway.WIB)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid date %q, must be YYYY-MM-DD", value))
+			return
+		}
+		to := from.AddDate(0, 0, 1)
+		request.From = &from
+		request.To = &to
+	}
+
+	reservations, err := apiCfg.DB.TableReservations.ListTableReservations(r.Context(), request)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't list reservations: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbTableReservationsToTableReservations(reservations))
+}
+
+func (apiCfg *apiConfig) handlerGetReservationByID(w http.ResponseWriter, r *http.Request) {
+	reservationID := r.PathValue("reservationID")
+
+	reservation, err := apiCfg.DB.TableReservations.GetTableReservationByID(r.Context(), reservationID)
+	if err != nil {
+		respondWithReservationError(w, reservationID, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbTableReservationToTableReservation(*reservation))
+}
+
+func (apiCfg *apiConfig) handlerCheckInReservation(w http.ResponseWriter, r *http.Request) {
+	apiCfg.updateReservationStatus(w, r, enums.StatusOccupied)
+}
+
+func (apiCfg *apiConfig) handlerCompleteReservation(w http.ResponseWriter, r *http.Request) {
+	apiCfg.updateReservationStatus(w, r, enums.StatusCompleted)
+}
+
+// * Customer cuma bisa batalin reservasi yang masih reserved, order yang belum dibayar ikut dibatalkan
+func (apiCfg *apiConfig) handlerCancelReservation(w http.ResponseWriter, r *http.Request) {
+	reservationID := r.PathValue("reservationID")
+
+	user, ok := requestUser(w, r)
+	if !ok {
+		return
+	}
+
+	reservation, err := database.CancelReservation(r.Context(), apiCfg.DB, apiCfg.PaymentGateway, reservationID, user.Role == enums.RoleAdmin)
+	switch {
+	case errors.Is(err, database.ErrReservationOccupied):
+		middleware.RespondForbidden(w, "Only admins can cancel an occupied reservation", enums.RoleAdmin)
+		return
+	case errors.Is(err, database.ErrReservationOrderActive), errors.Is(err, database.ErrOrderNotCancellable):
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, database.ErrPaymentGateway):
+		respondWithError(w, http.StatusBadGateway, err.Error())
+		return
+	case err != nil:
+		respondWithReservationError(w, reservationID, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbTableReservationToTableReservation(*reservation))
+}
+
+func (apiCfg *apiConfig) updateReservationStatus(w http.ResponseWriter, r *http.Request, status enums.ReservationStatus) {
+	reservationID := r.PathValue("reservationID")
+
+	reservation, err := apiCfg.DB.TableReservations.UpdateTableReservationStatus(r.Context(), reservationID, status)
+	if err != nil {
+		respondWithReservationError(w, reservationID, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbTableReservationToTableReservation(*reservation))
+}
+
+func respondWithReservationError(w http.ResponseWriter, reservationID string, err error) {
+	var transitionErr *database.InvalidTransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		respondWithInvalidTransition(w, transitionErr)
+	case errors.Is(err, database.ErrNotFound):
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Reservation %s not found", reservationID))
+	default:
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't update reservation %s: %v", reservationID, err))
+	}
+}
+
+func respondWithReservationConflict(w http.ResponseWriter, conflictErr *database.ReservationConflictError) {
+	type alternative struct {
+		TableID         string         `json:"tableId"`
+		TableNumber     string         `json:"tableNumber"`
+		Location        enums.Location `json:"location"`
+		Capacity        int            `json:"capacity"`
+		ReservationTime time.Time      `json:"reservationTime"`
+	}
+	type conflictResponse struct {
+		Error        string        `json:"error"`
+		TableID      string        `json:"tableId"`
+		Alternatives []alternative `json:"alternatives"`
+	}
+
+	alternatives := make([]alternative, len(conflictErr.Alternatives))
+	for i, alt := range conflictErr.Alternatives {
+		alternatives[i] = alternative{
+			TableID:         alt.TableID,
+			TableNumber:     alt.TableNumber,
+			Location:        alt.Location,
+			Capacity:        alt.Capacity,
+			ReservationTime: alt.ReservationTime,
+		}
+	}
+
+	respondWithJSON(w, http.StatusConflict, conflictResponse{
+		Error:        conflictErr.Error(),
+		TableID:      conflictErr.TableID,
+		Alternatives: alternatives,
+	})
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+)
+
+var tableLocations = []enums.Location{enums.LocationIndoor, enums.LocationOutdoor, enums.LocationVIP}
+
+func (apiCfg *apiConfig) handlerCreateTable(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		TableNumber string         `json:"tableNumber"`
+		Capacity    int            `json:"capacity"`
+		IsAvailable *bool          `json:"isAvailable,omitempty"` // Default true
+		Location    enums.Location `json:"location"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+	if params.TableNumber == "" || params.Capacity <= 0 {
+		respondWithError(w, http.StatusBadRequest, "tableNumber and a positive capacity are required")
+		return
+	}
+	if !slices.Contains(tableLocations, params.Location) {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("location must be one of %v", tableLocations))
+		return
+	}
+
+	isAvailable := true
+	if params.IsAvailable != nil {
+		isAvailable = *params.IsAvailable
+	}
+
+	table, err := apiCfg.DB.Tables.CreateRestaurantTable(r.Context(), database.CreateRestaurantTableRequest{
+		TableNumber: params.TableNumber,
+		Capacity:    params.Capacity,
+		IsAvailable: isAvailable,
+		Location:    params.Location,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't create table: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, dbRestaurantTableToRestaurantTable(*table))
+}
+
+func (apiCfg *apiConfig) handlerGetTables(w http.ResponseWriter, r *http.Request) {
+	tables, err := apiCfg.DB.Tables.GetAllRestaurantTables(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get tables: %v", err))
+		return
+	}
+
+	// * ?location= dan ?minCapacity= buat nyaring meja di layar pilih meja
+	query := r.URL.Query()
+	location := enums.Location(query.Get("location"))
+	if location != "" && !slices.Contains(tableLocations, location) {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("location must be one of %v", tableLocations))
+		return
+	}
+	minCapacity := 0
+	if value := query.Get("minCapacity"); value != "" {
+		minCapacity, err = strconv.Atoi(value)
+		if err != nil || minCapacity <= 0 {
+			respondWithError(w, http.StatusBadRequest, "minCapacity must be a positive integer")
+			return
+		}
+	}
+
+	filtered := []database.RestaurantTable{}
+	for _, table := range tables {
+		if location != "" && table.Location != location {
+			continue
+		}
+		if table.Capacity < minCapacity {
+			continue
+		}
+		filtered = append(filtered, table)
+	}
+
+	respondWithJSON(w, http.StatusOK, dbRestaurantTablesToRestaurantTables(filtered))
+}
+
+func (apiCfg *apiConfig) handlerGetTableAvailability(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// * ?date=2006-01-02 dihitung dalam WIB, default hari ini
+	date := time.Now().In(gateway.WIB)
+	if value := query.Get("date"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, gateway.WIB)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid date %q, must be YYYY-MM-DD", value))
+			return
+		}
+		date = parsed
+	}
+
+	partySize, err := strconv.Atoi(query.Get("partySize"))
+	if err != nil || partySize <= 0 {
+		respondWithError(w, http.StatusBadRequest, "partySize must be a positive integer")
+		return
+	}
+
+	location := enums.Location(query.Get("location"))
+	if location != "" && !slices.Contains(tableLocations, location) {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("location must be one of %v", tableLocations))
+		return
+	}
+
+	availability, err := database.GetTableAvailability(r.Context(), apiCfg.DB, database.TableAvailabilityRequest{
+		Date:            date,
+		PartySize:       partySize,
+		Location:        location,
+		OpeningHours:    apiCfg.OpeningHours,
+		SeatingDuration: apiCfg.ReservationSeatingDuration,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get table availability: %v", err))
+		return
+	}
+
+	type tableAvailability struct {
+		Table RestaurantTable `json:"table"`
+		Slots []time.Time     `json:"slots"` // Kirim salah satu ke tableReservation.reservationTime pas bikin order
+	}
+	type response struct {
+		Date            string              `json:"date"`
+		PartySize       int                 `json:"partySize"`
+		SeatingDuration string              `json:"seatingDuration"`
+		Tables          []tableAvailability `json:"tables"`
+	}
+
+	tables := make([]tableAvailability, len(availability))
+	for i, item := range availability {
+		tables[i] = tableAvailability{
+			Table: dbRestaurantTableToRestaurantTable(item.Table),
+			Slots: item.Slots,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, response{
+		Date:            date.Format(time.DateOnly),
+		PartySize:       partySize,
+		SeatingDuration: apiCfg.ReservationSeatingDuration.String(),
+		Tables:          tables,
+	})
+}
+
+func (apiCfg *apiConfig) handlerGetTableByID(w http.ResponseWriter, r *http.Request) {
+	tableID := r.PathValue("tableID")
+
+	table, err := apiCfg.DB.Tables.GetRestaurantTableByID(r.Context(), tableID)
+	if errors.Is(err, database.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Table %s not found", tableID))
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't get table %s: %v", tableID, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbRestaurantTableToRestaurantTable(*table))
+}
+
+func (apiCfg *apiConfig) handlerUpdateTable(w http.ResponseWriter, r *http.Request) {
+	tableID := r.PathValue("tableID")
+
+	type parameters struct {
+		TableNumber *string         `json:"tableNumber,omitempty"`
+		Capacity    *int            `json:"capacity,omitempty"`
+		IsAvailable *bool           `json:"isAvailable,omitempty"`
+		Location    *enums.Location `json:"location,omitempty"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+	if params.TableNumber != nil && *params.TableNumber == "" {
+		respondWithError(w, http.StatusBadRequest, "tableNumber cannot be empty")
+		return
+	}
+	if params.Capacity != nil && *params.Capacity <= 0 {
+		respondWithError(w, http.StatusBadRequest, "capacity must be positive")
+		return
+	}
+	if params.Location != nil && !slices.Contains(tableLocations, *params.Location) {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("location must be one of %v", tableLocations))
+		return
+	}
+
+	table, err := apiCfg.DB.Tables.UpdateRestaurantTable(r.Context(), tableID, database.UpdateRestaurantTableRequest{
+		TableNumber: params.TableNumber,
+		Capacity:    params.Capacity,
+		IsAvailable: params.IsAvailable,
+		Location:    params.Location,
+	})
+	if errors.Is(err, database.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Table %s not found", tableID))
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't update table %s: %v", tableID, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dbRestaurantTableToRestaurantTable(*table))
+}
+
+func (apiCfg *apiConfig) handlerDeleteTable(w http.ResponseWriter, r *http.Request) {
+	tableID := r.PathValue("tableID")
+
+	err := apiCfg.DB.Tables.DeleteRestaurantTable(r.Context(), tableID)
+	if errors.Is(err, database.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Table %s not found", tableID))
+		return
+	}
+	if errors.Is(err, database.ErrTableInUse) {
+		respondWithError(w, http.StatusConflict, fmt.Sprintf("Table %s still has reserved or occupied reservations, finish or cancel them first", tableID))
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't delete table %s: %v", tableID, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
-	"crypto/sha512"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/Rizz404/midtrans-handler/internal/database"
 	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+	"github.com/Rizz404/midtrans-handler/internal/paymentstatus"
+	"github.com/midtrans/midtrans-go/coreapi"
 )
 
 // MidtransNotificationPayload merepresentasikan data yang dikirim oleh Midtrans
@@ -24,8 +28,18 @@ type MidtransNotificationPayload struct {
 	GrossAmount       string `json:"gross_amount"`
 	FraudStatus       string `json:"fraud_status"`
 	PaymentType       string `json:"payment_type"`
+	RefundAmount      string `json:"refund_amount"` // Total yang sudah di-refund, cuma ada di notifikasi refund
+
+	Refunds []coreapi.RefundDetails `json:"refunds"` // Rincian tiap refund, cuma ada di notifikasi refund
 }
 
+const (
+	notificationResultApplied = "applied"
+	notificationResultIgnored = "ignored"
+	// * Notifikasi valid signature-nya tapi isinya gak cocok dengan order kita
+	notificationResultRejected = "rejected"
+)
+
 func (apiCfg *apiConfig) handlerMidtransWebhook(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -40,56 +54,174 @@ func (apiCfg *apiConfig) handlerMidtransWebhook(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	signature := generateSignatureKey(payload.OrderID, payload.StatusCode, payload.GrossAmount, apiCfg.MidtransServerKey)
-	if signature != payload.SignatureKey {
+	if !gateway.VerifySignature(payload.OrderID, payload.StatusCode, payload.GrossAmount, apiCfg.MidtransServerKey, payload.SignatureKey) {
 		respondWithError(w, http.StatusUnauthorized, "Invalid signature")
 		return
 	}
 
-	updateReq := database.UpdateOrderRequest{}
-	var paymentStatus enums.PaymentStatus
-	var orderStatus enums.OrderStatus
+	notificationID := paymentNotificationID(payload)
+	err = apiCfg.DB.Notifications.CreatePaymentNotification(r.Context(), database.PaymentNotification{
+		ID:                notificationID,
+		OrderID:           payload.OrderID,
+		TransactionID:     payload.TransactionID,
+		TransactionStatus: payload.TransactionStatus,
+		FraudStatus:       payload.FraudStatus,
+		StatusCode:        payload.StatusCode,
+		GrossAmount:       payload.GrossAmount,
+		PaymentType:       payload.PaymentType,
+		MerchantID:        payload.MerchantID,
+	})
+	if errors.Is(err, database.ErrAlreadyExists) {
+		existing, err := apiCfg.DB.Notifications.GetPaymentNotificationByID(r.Context(), notificationID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't load payment notification %s: %v", notificationID, err))
+			return
+		}
+		// * Yang pernah di-reject diverifikasi ulang, biar retry Midtrans dapet jawaban yang sama
+		if existing.Result != "" && existing.Result != notificationResultRejected {
+			respondWithJSON(w, http.StatusOK, map[string]string{"message": "Duplicate notification, already processed"})
+			return
+		}
+		// * Sudah tercatat tapi belum selesai diproses (request sebelumnya gagal), proses ulang
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't record payment notification: %v", err))
+		return
+	}
+
+	order, err := apiCfg.DB.Orders.GetOrderByID(r.Context(), payload.OrderID)
+	if errors.Is(err, database.ErrNotFound) {
+		apiCfg.rejectNotification(w, r, notificationID, http.StatusNotFound, fmt.Sprintf("order %s not found", payload.OrderID))
+		return
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't load order %s: %v", payload.OrderID, err))
+		return
+	}
+
+	// * Body mentah disimpan apa adanya (semua field, bukan cuma yang ada di MidtransNotificationPayload)
+	var rawPayload map[string]any
+	if err := json.Unmarshal(body, &rawPayload); err == nil {
+		err = apiCfg.DB.PaymentEvents.RecordPaymentEvent(r.Context(), database.PaymentEvent{
+			ID:                notificationID,
+			OrderID:           payload.OrderID,
+			Source:            database.PaymentEventSourceNotification,
+			TransactionStatus: payload.TransactionStatus,
+			Payload:           rawPayload,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't record payment event: %v", err))
+			return
+		}
+	}
+
+	// * Charge-nya belum selesai difinalisasi, gak di-mark processed biar retry Midtrans diproses nanti
+	if order.Status == enums.OrderStatusCreating {
+		respondWithError(w, http.StatusConflict, fmt.Sprintf("Order %s is still being created", payload.OrderID))
+		return
+	}
 
-	switch payload.TransactionStatus {
-	case "capture":
-		switch payload.FraudStatus {
-		case "accept":
-			paymentStatus = enums.PaymentStatusSuccess
-			orderStatus = enums.OrderStatusConfirmed
-		case "challenge":
-			paymentStatus = enums.PaymentStatusChallenge
+	if reason := verifyNotificationAgainstOrder(payload, *order, apiCfg.MidtransMerchantID); reason != "" {
+		apiCfg.rejectNotification(w, r, notificationID, http.StatusBadRequest, reason)
+		return
+	}
+
+	mapped := paymentstatus.FromMidtrans(payload.TransactionStatus, payload.FraudStatus)
+	if mapped.Outcome != paymentstatus.OutcomeUpdate {
+		if mapped.Outcome == paymentstatus.OutcomeUnknown {
+			fmt.Printf("WEBHOOK_WARNING: Order %s: %s\n", payload.OrderID, mapped.Reason)
 		}
-	case "settlement":
-		paymentStatus = enums.PaymentStatusSuccess
-		orderStatus = enums.OrderStatusConfirmed
-	case "deny":
-		paymentStatus = enums.PaymentStatusDeny
-		orderStatus = enums.OrderStatusCancelled
-	case "cancel", "expire":
-		paymentStatus = enums.PaymentStatusFailure
-		orderStatus = enums.OrderStatusCancelled
-	case "pending":
-		paymentStatus = enums.PaymentStatusPending
-		orderStatus = enums.OrderStatusPending
-	default:
+		apiCfg.markNotificationProcessed(r, notificationID, notificationResultIgnored, "")
 		respondWithJSON(w, http.StatusOK, map[string]string{"message": "Webhook received, no action taken"})
 		return
 	}
 
-	updateReq.PaymentStatus = &paymentStatus
-	updateReq.OrderStatus = &orderStatus
+	// * UpdateOrder cuma nerima transisi maju sesuai tabel di enums,
+	// * jadi notifikasi telat / out-of-order ditolak dan cukup diabaikan.
+	// * Refund dicatat lengkap (nominal + riwayat), refund yang sudah tercatat lewat API dilewati.
+	var transitionErr *database.InvalidTransitionError
+	if paymentstatus.IsRefund(payload.TransactionStatus) {
+		_, err = database.RecordGatewayRefunds(r.Context(), apiCfg.DB, payload.OrderID, database.GatewayRefunds{
+			RefundAmount: payload.RefundAmount,
+			Refunds:      payload.Refunds,
+			FallbackKey:  notificationID,
+		})
+	} else {
+		_, err = apiCfg.DB.Orders.UpdateOrder(r.Context(), payload.OrderID, database.UpdateOrderRequest{
+			PaymentStatus:   mapped.PaymentStatus,
+			OrderStatus:     mapped.OrderStatus,
+			SyncReservation: true,
+		})
+	}
+	switch {
+	case errors.Is(err, database.ErrOrderNotRefundable), errors.Is(err, database.ErrInvalidRefund):
+		fmt.Printf("WEBHOOK_WARNING: Order %s: refund not recorded: %v\n", payload.OrderID, err)
+		apiCfg.markNotificationProcessed(r, notificationID, notificationResultIgnored, "")
+		respondWithJSON(w, http.StatusOK, map[string]string{"message": "Webhook received, refund ignored"})
+		return
+	case errors.As(err, &transitionErr):
+		apiCfg.markNotificationProcessed(r, notificationID, notificationResultIgnored, "")
+		respondWithJSON(w, http.StatusOK, map[string]string{"message": "Webhook received, stale status ignored"})
+		return
+	case errors.Is(err, database.ErrNotFound):
+		apiCfg.rejectNotification(w, r, notificationID, http.StatusNotFound, fmt.Sprintf("order %s not found", payload.OrderID))
+		return
+	case err != nil:
+		// * Gak di-mark processed, jadi retry dari Midtrans bakal diproses ulang
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't update order %s: %v", payload.OrderID, err))
+		return
+	}
+
+	apiCfg.markNotificationProcessed(r, notificationID, notificationResultApplied, "")
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Webhook processed successfully"})
+}
+
+// * Satu transaksi bisa dapet beberapa notifikasi capture (challenge lalu accept),
+// * jadi fraud status selain accept ikut jadi bagian key.
+func paymentNotificationID(payload MidtransNotificationPayload) string {
+	id := payload.TransactionID + "_" + payload.TransactionStatus
+	if payload.FraudStatus != "" && payload.FraudStatus != "accept" {
+		id += "_" + payload.FraudStatus
+	}
+	// * Partial refund bisa berkali-kali, bedanya cuma di total refund_amount
+	if payload.RefundAmount != "" {
+		id += "_" + payload.RefundAmount
+	}
+	return id
+}
+
+// * verifyNotificationAgainstOrder ngembaliin alasan penolakan, kosong kalau notifikasi cocok dengan yang kita charge
+func verifyNotificationAgainstOrder(payload MidtransNotificationPayload, order database.Order, merchantID string) string {
+	if order.ManualPayment {
+		return "order uses manual payment and has no Midtrans transaction"
+	}
 
-	_, err = database.UpdateOrder(r.Context(), apiCfg.Firestore, payload.OrderID, updateReq)
+	grossAmount, err := strconv.ParseFloat(payload.GrossAmount, 64)
 	if err != nil {
-		fmt.Printf("WEBHOOK_ERROR: Failed to update order %s: %v\n", payload.OrderID, err)
+		return fmt.Sprintf("invalid gross_amount %q", payload.GrossAmount)
+	}
+	if grossAmount != order.TotalAmount {
+		return fmt.Sprintf("gross_amount %s does not match order total %.2f", payload.GrossAmount, order.TotalAmount)
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Webhook processed successfully"})
+	if order.PaymentType != nil && payload.PaymentType != *order.PaymentType {
+		return fmt.Sprintf("payment_type %s does not match charged payment type %s", payload.PaymentType, *order.PaymentType)
+	}
+
+	// * Merchant cuma dicek kalau MIDTRANS_MERCHANT_ID di-set
+	if merchantID != "" && payload.MerchantID != merchantID {
+		return fmt.Sprintf("merchant_id %s does not match", payload.MerchantID)
+	}
+
+	return ""
 }
 
-func generateSignatureKey(orderID, statusCode, grossAmount, serverKey string) string {
-	str := fmt.Sprintf("%s%s%s%s", orderID, statusCode, grossAmount, serverKey)
-	hasher := sha512.New()
-	hasher.Write([]byte(str))
-	return fmt.Sprintf("%x", hasher.Sum(nil))
+func (apiCfg *apiConfig) rejectNotification(w http.ResponseWriter, r *http.Request, notificationID string, code int, reason string) {
+	fmt.Printf("WEBHOOK_REJECTED: Notification %s: %s\n", notificationID, reason)
+	apiCfg.markNotificationProcessed(r, notificationID, notificationResultRejected, reason)
+	respondWithError(w, code, reason)
+}
+
+func (apiCfg *apiConfig) markNotificationProcessed(r *http.Request, notificationID, result, reason string) {
+	if err := apiCfg.DB.Notifications.MarkPaymentNotificationProcessed(r.Context(), notificationID, result, reason); err != nil {
+		fmt.Printf("WEBHOOK_ERROR: Failed to mark notification %s as %s: %v\n", notificationID, result, err)
+	}
 }
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotencyKeyMaxLength = 255
+	idempotencyKeyTTL       = 24 * time.Hour
+)
+
+// * idempotencyResponseRecorder nyalin status dan body response biar bisa disimpan lalu di-replay
+type idempotencyResponseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *idempotencyResponseRecorder) WriteHeader(code int) {
+	rec.status = code
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *idempotencyResponseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// * idempotencyMiddleware nyimpen response pertama per user + Idempotency-Key selama 24 jam dan
+// * nge-replay response itu kalau client retry. Request tanpa header diteruskan apa adanya.
+// * Harus dipasang setelah AuthMiddleware, key yang sama punya user lain dianggap beda.
+func (apiCfg *apiConfig) idempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, ok := requestUser(w, r)
+		if !ok {
+			return
+		}
+		if len(key) > idempotencyKeyMaxLength {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("%s must be at most %d characters", idempotencyKeyHeader, idempotencyKeyMaxLength))
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error reading request body: %v", err))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		record := database.IdempotencyKey{
+			ID:          hashHex(user.ID + "\x00" + key),
+			UserID:      user.ID,
+			Key:         key,
+			RequestHash: hashHex(string(body)),
+			ExpiresAt:   time.Now().Add(idempotencyKeyTTL),
+		}
+
+		existing, err := apiCfg.DB.IdempotencyKeys.ReserveIdempotencyKey(r.Context(), record)
+		if errors.Is(err, database.ErrAlreadyExists) && existing != nil {
+			if existing.Status == database.IdempotencyStatusCompleted && existing.ResponseStatus == http.StatusAccepted && existing.RequestHash == record.RequestHash {
+				refreshed := apiCfg.refreshAcceptedOrderResponse(r, *existing)
+				existing = &refreshed
+			}
+			replayIdempotentResponse(w, *existing, record.RequestHash)
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Couldn't reserve idempotency key: %v", err))
+			return
+		}
+
+		rec := &idempotencyResponseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		// * Response 5xx gak disimpan biar client bisa retry pakai key yang sama. Handler wajib gak
+		// * ngembaliin 5xx setelah efek samping yang gak boleh diulang (misalnya charge Midtrans).
+		if rec.status == 0 || rec.status >= http.StatusInternalServerError {
+			if err := apiCfg.DB.IdempotencyKeys.DeleteIdempotencyKey(r.Context(), record.ID); err != nil {
+				log.Printf("IDEMPOTENCY: Failed to release key %s: %v", record.ID, err)
+			}
+			return
+		}
+		if err := apiCfg.DB.IdempotencyKeys.CompleteIdempotencyKey(r.Context(), record.ID, rec.status, rec.body.String()); err != nil {
+			log.Printf("IDEMPOTENCY: Failed to store response for key %s: %v", record.ID, err)
+		}
+	})
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, existing database.IdempotencyKey, requestHash string) {
+	if existing.RequestHash != requestHash {
+		respondWithError(w, http.StatusConflict, fmt.Sprintf("%s was already used with a different request body", idempotencyKeyHeader))
+		return
+	}
+	if existing.Status != database.IdempotencyStatusCompleted {
+		respondWithError(w, http.StatusConflict, fmt.Sprintf("A request with this %s is still being processed", idempotencyKeyHeader))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(existing.ResponseStatus)
+	w.Write([]byte(existing.ResponseBody))
+}
+
+// * refreshAcceptedOrderResponse: 202 berarti order masih creating tanpa instruksi pembayaran, jadi replay-nya
+// * baca ulang order. Kalau outbox worker sudah selesai, response 201 yang baru disimpan dan key gak dibaca ulang lagi.
+// * Key gak dilepas, retry dengan key yang sama gak boleh bikin charge baru.
+func (apiCfg *apiConfig) refreshAcceptedOrderResponse(r *http.Request, existing database.IdempotencyKey) database.IdempotencyKey {
+	var cached Order
+	if err := json.Unmarshal([]byte(existing.ResponseBody), &cached); err != nil || cached.ID == "" {
+		log.Printf("IDEMPOTENCY: Stored 202 response for key %s has no order ID: %v", existing.ID, err)
+		return existing
+	}
+
+	order, err := apiCfg.DB.Orders.GetOrderByID(r.Context(), cached.ID)
+	if err != nil {
+		log.Printf("IDEMPOTENCY: Failed to refresh order %s for key %s: %v", cached.ID, existing.ID, err)
+		return existing
+	}
+	body, err := json.Marshal(dbOrderToOrder(*order))
+	if err != nil {
+		log.Printf("IDEMPOTENCY: Failed to encode order %s for key %s: %v", order.ID, existing.ID, err)
+		return existing
+	}
+
+	existing.ResponseBody = string(body)
+	if order.Status == enums.OrderStatusCreating {
+		return existing
+	}
+
+	existing.ResponseStatus = http.StatusCreated
+	if err := apiCfg.DB.IdempotencyKeys.CompleteIdempotencyKey(r.Context(), existing.ID, existing.ResponseStatus, existing.ResponseBody); err != nil {
+		log.Printf("IDEMPOTENCY: Failed to store refreshed response for key %s: %v", existing.ID, err)
+	}
+	return existing
+}
+
+func hashHex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+)
+
+var (
+	// * ErrCategoryNotFound dipakai waktu menu item nunjuk ke categoryId yang gak ada (input salah, bukan 404)
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryInUse    = errors.New("category still has menu items")
+)
+
+type CreateCategoryRequest struct {
+	Name        string
+	Description *string
+}
+
+// * UpdateCategoryRequest: field nil gak diubah, Description "" buat ngehapus deskripsi
+type UpdateCategoryRequest struct {
+	Name        *string
+	Description *string
+}
+
+type firestoreCategoryRepository struct {
+	client *firestore.Client
+}
+
+func (r *firestoreCategoryRepository) CreateCategory(ctx context.Context, request CreateCategoryRequest) (*Category, error) {
+	docRef := r.client.Collection("categories").NewDoc()
+
+	data := map[string]any{
+		"id":        docRef.ID,
+		"name":      request.Name,
+		"createdAt": firestore.ServerTimestamp,
+		"updatedAt": firestore.ServerTimestamp,
+	}
+	if request.Description != nil {
+		data["description"] = *request.Description
+	}
+
+	if _, err := docRef.Set(ctx, data); err != nil {
+		return nil, fmt.Errorf("failed to create category: %v", err)
+	}
+
+	return r.GetCategoryByID(ctx, docRef.ID)
+}
+
+func (r *firestoreCategoryRepository) GetAllCategories(ctx context.Context) ([]Category, error) {
+	docs, err := r.client.Collection("categories").OrderBy("name", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %v", err)
+	}
+
+	categories := make([]Category, 0, len(docs))
+	for _, doc := range docs {
+		var category Category
+		if err := doc.DataTo(&category); err != nil {
+			return nil, fmt.Errorf("failed to decode category %s: %v", doc.Ref.ID, err)
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
+
+func (r *firestoreCategoryRepository) GetCategoryByID(ctx context.Context, id string) (*Category, error) {
+	docSnapshot, err := r.client.Collection("categories").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category %s: %w", id, mapFirestoreError(err))
+	}
+
+	var category Category
+	if err := docSnapshot.DataTo(&category); err != nil {
+		return nil, fmt.Errorf("failed to decode category %s: %v", id, err)
+	}
+
+	return &category, nil
+}
+
+func (r *firestoreCategoryRepository) UpdateCategory(ctx context.Context, id string, request UpdateCategoryRequest) (*Category, error) {
+	if request.Name == nil && request.Description == nil {
+		return r.GetCategoryByID(ctx, id)
+	}
+
+	docRef := r.client.Collection("categories").Doc(id)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// * Firestore transaction: semua read harus sebelum write
+		if _, err := tx.Get(docRef); err != nil {
+			return mapFirestoreError(err)
+		}
+		menuItemDocs, err := tx.Documents(r.client.Collection("menuItems").Where("categoryId", "==", id)).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to query menu items of category %s: %v", id, err)
+		}
+
+		updates := []firestore.Update{}
+		if request.Name != nil {
+			updates = append(updates, firestore.Update{Path: "name", Value: *request.Name})
+		}
+		if request.Description != nil {
+			var description any = *request.Description
+			if *request.Description == "" {
+				description = firestore.Delete
+			}
+			updates = append(updates, firestore.Update{Path: "description", Value: description})
+		}
+		updates = append(updates, firestore.Update{Path: "updatedAt", Value: firestore.ServerTimestamp})
+
+		if err := tx.Update(docRef, updates); err != nil {
+			return err
+		}
+
+		// * Salinan kategori di menu item ikut diperbarui biar gak basi
+		menuItemUpdates := make([]firestore.Update, len(updates))
+		for i, update := range updates {
+			menuItemUpdates[i] = firestore.Update{Path: "category." + update.Path, Value: update.Value}
+		}
+		for _, doc := range menuItemDocs {
+			if err := tx.Update(doc.Ref, menuItemUpdates); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update category %s: %w", id, err)
+	}
+
+	return r.GetCategoryByID(ctx, id)
+}
+
+// * DeleteCategory gagal dengan ErrCategoryInUse kalau masih ada menu item di kategori itu
+func (r *firestoreCategoryRepository) DeleteCategory(ctx context.Context, id string) error {
+	docRef := r.client.Collection("categories").Doc(id)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		menuItemDocs, err := tx.Documents(r.client.Collection("menuItems").Where("categoryId", "==", id).Limit(1)).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to query menu items of category %s: %v", id, err)
+		}
+		if len(menuItemDocs) > 0 {
+			return ErrCategoryInUse
+		}
+		return tx.Delete(docRef)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete category %s: %w", id, err)
+	}
+	return nil
+}
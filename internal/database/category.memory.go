@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type memoryCategoryRepository struct {
+	db *MemoryDB
+}
+
+func (r *memoryCategoryRepository) CreateCategory(ctx context.Context, request CreateCategoryRequest) (*Category, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	category := Category{
+		ID:          newMemoryID(),
+		Name:        request.Name,
+		Description: request.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	r.db.categories[category.ID] = category
+
+	return &category, nil
+}
+
+func (r *memoryCategoryRepository) GetAllCategories(ctx context.Context) ([]Category, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	categories := make([]Category, 0, len(r.db.categories))
+	for _, category := range r.db.categories {
+		categories = append(categories, category)
+	}
+	// * Sama kayak Firestore: urut berdasarkan name
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Name < categories[j].Name
+	})
+
+	return categories, nil
+}
+
+func (r *memoryCategoryRepository) GetCategoryByID(ctx context.Context, id string) (*Category, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	category, ok := r.db.categories[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get category %s: %w", id, ErrNotFound)
+	}
+
+	return &category, nil
+}
+
+func (r *memoryCategoryRepository) UpdateCategory(ctx context.Context, id string, request UpdateCategoryRequest) (*Category, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	category, ok := r.db.categories[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to update category %s: %w", id, ErrNotFound)
+	}
+	if request.Name == nil && request.Description == nil {
+		return &category, nil
+	}
+
+	if request.Name != nil {
+		category.Name = *request.Name
+	}
+	if request.Description != nil {
+		category.Description = request.Description
+		if *request.Description == "" {
+			category.Description = nil
+		}
+	}
+	category.UpdatedAt = time.Now()
+	r.db.categories[id] = category
+
+	for menuItemID, menuItem := range r.db.menuItems {
+		if menuItem.CategoryId != nil && *menuItem.CategoryId == id {
+			embedded := category
+			menuItem.Category = &embedded
+			r.db.menuItems[menuItemID] = menuItem
+		}
+	}
+
+	return &category, nil
+}
+
+func (r *memoryCategoryRepository) DeleteCategory(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, menuItem := range r.db.menuItems {
+		if menuItem.CategoryId != nil && *menuItem.CategoryId == id {
+			return fmt.Errorf("failed to delete category %s: %w", id, ErrCategoryInUse)
+		}
+	}
+
+	delete(r.db.categories, id)
+	return nil
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemoryDeleteCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		withItem bool
+		want     error
+	}{
+		{name: "unused category"},
+		{name: "category in use", withItem: true, want: ErrCategoryInUse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			ctx := context.Background()
+
+			category, err := store.Categories.CreateCategory(ctx, CreateCategoryRequest{Name: "Minuman"})
+			if err != nil {
+				t.Fatalf("CreateCategory: %v", err)
+			}
+			if tt.withItem {
+				_, err := store.MenuItems.CreateMenuItem(ctx, CreateMenuItemRequest{Name: "Es Teh", Price: 5000, CategoryId: &category.ID})
+				if err != nil {
+					t.Fatalf("CreateMenuItem: %v", err)
+				}
+			}
+
+			err = store.Categories.DeleteCategory(ctx, category.ID)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("DeleteCategory error = %v, want %v", err, tt.want)
+			}
+
+			_, err = store.Categories.GetCategoryByID(ctx, category.ID)
+			if deleted := errors.Is(err, ErrNotFound); deleted != (tt.want == nil) {
+				t.Errorf("category deleted = %v, want %v", deleted, tt.want == nil)
+			}
+		})
+	}
+}
+
+func TestMemoryUpdateCategoryUpdatesEmbeddedCategory(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	category, err := store.Categories.CreateCategory(ctx, CreateCategoryRequest{Name: "Minuman"})
+	if err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	menuItem, err := store.MenuItems.CreateMenuItem(ctx, CreateMenuItemRequest{Name: "Es Teh", Price: 5000, CategoryId: &category.ID})
+	if err != nil {
+		t.Fatalf("CreateMenuItem: %v", err)
+	}
+
+	name := "Minuman Dingin"
+	if _, err := store.Categories.UpdateCategory(ctx, category.ID, UpdateCategoryRequest{Name: &name}); err != nil {
+		t.Fatalf("UpdateCategory: %v", err)
+	}
+
+	got, _ := store.MenuItems.GetMenuItemByID(ctx, menuItem.ID)
+	if got.Category == nil || got.Category.Name != name {
+		t.Errorf("embedded category = %+v, want name %q", got.Category, name)
+	}
+}
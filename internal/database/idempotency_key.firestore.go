@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+type firestoreIdempotencyKeyRepository struct {
+	client *firestore.Client
+}
+
+func (r *firestoreIdempotencyKeyRepository) ReserveIdempotencyKey(ctx context.Context, key IdempotencyKey) (*IdempotencyKey, error) {
+	docRef := r.client.Collection("idempotencyKeys").Doc(key.ID)
+	key.Status = IdempotencyStatusProcessing
+	key.ResponseStatus = 0
+	key.ResponseBody = ""
+	key.CreatedAt = time.Now()
+
+	var existing *IdempotencyKey
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		existing = nil
+		docSnapshot, err := tx.Get(docRef)
+		if err != nil && !errors.Is(mapFirestoreError(err), ErrNotFound) {
+			return err
+		}
+		if err == nil {
+			var current IdempotencyKey
+			if err := docSnapshot.DataTo(&current); err != nil {
+				return fmt.Errorf("failed to decode idempotency key %s: %v", key.ID, err)
+			}
+			// * TTL Firestore gak langsung hapus dokumen, jadi expiresAt tetap dicek di sini
+			if current.ExpiresAt.After(time.Now()) {
+				existing = &current
+				return ErrAlreadyExists
+			}
+		}
+		return tx.Set(docRef, key)
+	})
+	if err != nil {
+		return existing, fmt.Errorf("failed to reserve idempotency key %s: %w", key.ID, err)
+	}
+
+	return &key, nil
+}
+
+func (r *firestoreIdempotencyKeyRepository) CompleteIdempotencyKey(ctx context.Context, id string, responseStatus int, responseBody string) error {
+	_, err := r.client.Collection("idempotencyKeys").Doc(id).Update(ctx, []firestore.Update{
+		{Path: "status", Value: IdempotencyStatusCompleted},
+		{Path: "responseStatus", Value: responseStatus},
+		{Path: "responseBody", Value: responseBody},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key %s: %w", id, mapFirestoreError(err))
+	}
+	return nil
+}
+
+func (r *firestoreIdempotencyKeyRepository) DeleteIdempotencyKey(ctx context.Context, id string) error {
+	if _, err := r.client.Collection("idempotencyKeys").Doc(id).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete idempotency key %s: %v", id, err)
+	}
+	return nil
+}
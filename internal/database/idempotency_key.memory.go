@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type memoryIdempotencyKeyRepository struct {
+	db *MemoryDB
+}
+
+func (r *memoryIdempotencyKeyRepository) ReserveIdempotencyKey(ctx context.Context, key IdempotencyKey) (*IdempotencyKey, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if existing, exists := r.db.idempotencyKeys[key.ID]; exists && existing.ExpiresAt.After(time.Now()) {
+		return &existing, fmt.Errorf("failed to reserve idempotency key %s: %w", key.ID, ErrAlreadyExists)
+	}
+
+	key.Status = IdempotencyStatusProcessing
+	key.ResponseStatus = 0
+	key.ResponseBody = ""
+	key.CreatedAt = time.Now()
+	r.db.idempotencyKeys[key.ID] = key
+
+	return &key, nil
+}
+
+func (r *memoryIdempotencyKeyRepository) CompleteIdempotencyKey(ctx context.Context, id string, responseStatus int, responseBody string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	key, ok := r.db.idempotencyKeys[id]
+	if !ok {
+		return fmt.Errorf("failed to complete idempotency key %s: %w", id, ErrNotFound)
+	}
+
+	key.Status = IdempotencyStatusCompleted
+	key.ResponseStatus = responseStatus
+	key.ResponseBody = responseBody
+	r.db.idempotencyKeys[id] = key
+
+	return nil
+}
+
+func (r *memoryIdempotencyKeyRepository) DeleteIdempotencyKey(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	delete(r.db.idempotencyKeys, id)
+	return nil
+}
@@ -0,0 +1,222 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+)
+
+type CreateMenuItemRequest struct {
+	Name        string
+	Price       float64
+	ImageUrl    *string
+	CategoryId  *string
+	IsAvailable bool
+}
+
+// * UpdateMenuItemRequest: field nil gak diubah, ImageUrl / CategoryId "" buat ngehapus
+type UpdateMenuItemRequest struct {
+	Name        *string
+	Price       *float64
+	ImageUrl    *string
+	CategoryId  *string
+	IsAvailable *bool
+}
+
+// * ListMenuItemsRequest: field kosong / nil berarti gak difilter
+type ListMenuItemsRequest struct {
+	CategoryID  string
+	IsAvailable *bool
+}
+
+type firestoreMenuItemRepository struct {
+	client *firestore.Client
+}
+
+// * getCategoryInTx ngembaliin ErrCategoryNotFound kalau categoryId-nya gak ada
+func (r *firestoreMenuItemRepository) getCategoryInTx(tx *firestore.Transaction, categoryID string) (*Category, error) {
+	docSnapshot, err := tx.Get(r.client.Collection("categories").Doc(categoryID))
+	if errors.Is(mapFirestoreError(err), ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, categoryID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category %s: %v", categoryID, err)
+	}
+
+	var category Category
+	if err := docSnapshot.DataTo(&category); err != nil {
+		return nil, fmt.Errorf("failed to decode category %s: %v", categoryID, err)
+	}
+	return &category, nil
+}
+
+func (r *firestoreMenuItemRepository) CreateMenuItem(ctx context.Context, request CreateMenuItemRequest) (*DenormalizedMenuItem, error) {
+	docRef := r.client.Collection("menuItems").NewDoc()
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		data := map[string]any{
+			"id":          docRef.ID,
+			"name":        request.Name,
+			"price":       request.Price,
+			"isAvailable": request.IsAvailable,
+			"createdAt":   firestore.ServerTimestamp,
+			"updatedAt":   firestore.ServerTimestamp,
+		}
+		if request.ImageUrl != nil {
+			data["imageUrl"] = *request.ImageUrl
+		}
+		// * Kategori disalin ke menu item biar order gak perlu baca koleksi categories
+		if request.CategoryId != nil {
+			category, err := r.getCategoryInTx(tx, *request.CategoryId)
+			if err != nil {
+				return err
+			}
+			data["categoryId"] = category.ID
+			data["category"] = *category
+		}
+		return tx.Create(docRef, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create menu item: %w", err)
+	}
+
+	return r.GetMenuItemByID(ctx, docRef.ID)
+}
+
+func (r *firestoreMenuItemRepository) ListMenuItems(ctx context.Context, request ListMenuItemsRequest) ([]DenormalizedMenuItem, error) {
+	query := r.client.Collection("menuItems").Query
+	if request.CategoryID != "" {
+		query = query.Where("categoryId", "==", request.CategoryID)
+	}
+	if request.IsAvailable != nil {
+		query = query.Where("isAvailable", "==", *request.IsAvailable)
+	}
+
+	docs, err := query.OrderBy("name", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query menu items: %v", err)
+	}
+
+	menuItems := make([]DenormalizedMenuItem, 0, len(docs))
+	for _, doc := range docs {
+		var menuItem DenormalizedMenuItem
+		if err := doc.DataTo(&menuItem); err != nil {
+			return nil, fmt.Errorf("failed to decode menu item %s: %v", doc.Ref.ID, err)
+		}
+		menuItems = append(menuItems, menuItem)
+	}
+
+	return menuItems, nil
+}
+
+func (r *firestoreMenuItemRepository) GetMenuItemByID(ctx context.Context, id string) (*DenormalizedMenuItem, error) {
+	docSnapshot, err := r.client.Collection("menuItems").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get menu item %s: %w", id, mapFirestoreError(err))
+	}
+
+	var menuItem DenormalizedMenuItem
+	if err := docSnapshot.DataTo(&menuItem); err != nil {
+		return nil, fmt.Errorf("failed to decode menu item %s: %v", id, err)
+	}
+
+	return &menuItem, nil
+}
+
+func (r *firestoreMenuItemRepository) GetMenuItemsByIDs(ctx context.Context, ids []string) (map[string]DenormalizedMenuItem, error) {
+	menuItems := map[string]DenormalizedMenuItem{}
+	if len(ids) == 0 {
+		return menuItems, nil
+	}
+
+	docRefs := make([]*firestore.DocumentRef, 0, len(ids))
+	for _, id := range ids {
+		docRefs = append(docRefs, r.client.Collection("menuItems").Doc(id))
+	}
+
+	// * GetAll tetap ngembaliin snapshot buat dokumen yang gak ada, cek lewat Exists()
+	docs, err := r.client.GetAll(ctx, docRefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get menu items: %v", err)
+	}
+
+	for _, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+		var menuItem DenormalizedMenuItem
+		if err := doc.DataTo(&menuItem); err != nil {
+			return nil, fmt.Errorf("failed to decode menu item %s: %v", doc.Ref.ID, err)
+		}
+		menuItems[doc.Ref.ID] = menuItem
+	}
+
+	return menuItems, nil
+}
+
+func (r *firestoreMenuItemRepository) UpdateMenuItem(ctx context.Context, id string, request UpdateMenuItemRequest) (*DenormalizedMenuItem, error) {
+	docRef := r.client.Collection("menuItems").Doc(id)
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// * Firestore transaction: semua read harus sebelum write
+		if _, err := tx.Get(docRef); err != nil {
+			return mapFirestoreError(err)
+		}
+
+		updates := []firestore.Update{}
+		if request.CategoryId != nil {
+			if *request.CategoryId == "" {
+				updates = append(updates,
+					firestore.Update{Path: "categoryId", Value: firestore.Delete},
+					firestore.Update{Path: "category", Value: firestore.Delete},
+				)
+			} else {
+				category, err := r.getCategoryInTx(tx, *request.CategoryId)
+				if err != nil {
+					return err
+				}
+				updates = append(updates,
+					firestore.Update{Path: "categoryId", Value: category.ID},
+					firestore.Update{Path: "category", Value: *category},
+				)
+			}
+		}
+		if request.Name != nil {
+			updates = append(updates, firestore.Update{Path: "name", Value: *request.Name})
+		}
+		if request.Price != nil {
+			updates = append(updates, firestore.Update{Path: "price", Value: *request.Price})
+		}
+		if request.ImageUrl != nil {
+			var imageUrl any = *request.ImageUrl
+			if *request.ImageUrl == "" {
+				imageUrl = firestore.Delete
+			}
+			updates = append(updates, firestore.Update{Path: "imageUrl", Value: imageUrl})
+		}
+		if request.IsAvailable != nil {
+			updates = append(updates, firestore.Update{Path: "isAvailable", Value: *request.IsAvailable})
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		updates = append(updates, firestore.Update{Path: "updatedAt", Value: firestore.ServerTimestamp})
+
+		return tx.Update(docRef, updates)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update menu item %s: %w", id, err)
+	}
+
+	return r.GetMenuItemByID(ctx, id)
+}
+
+func (r *firestoreMenuItemRepository) DeleteMenuItem(ctx context.Context, id string) error {
+	// * firestore.Exists biar ID yang gak ada jadi ErrNotFound, bukan delete kosong yang sukses
+	if _, err := r.client.Collection("menuItems").Doc(id).Delete(ctx, firestore.Exists); err != nil {
+		return fmt.Errorf("failed to delete menu item %s: %w", id, mapFirestoreError(err))
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type memoryMenuItemRepository struct {
+	db *MemoryDB
+}
+
+// * embeddedCategoryLocked harus dipanggil sambil pegang db.mu
+func (r *memoryMenuItemRepository) embeddedCategoryLocked(categoryID string) (*Category, error) {
+	category, ok := r.db.categories[categoryID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, categoryID)
+	}
+	return &category, nil
+}
+
+func (r *memoryMenuItemRepository) CreateMenuItem(ctx context.Context, request CreateMenuItemRequest) (*DenormalizedMenuItem, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	menuItem := DenormalizedMenuItem{
+		ID:          newMemoryID(),
+		Name:        request.Name,
+		Price:       request.Price,
+		ImageUrl:    request.ImageUrl,
+		IsAvailable: request.IsAvailable,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if request.CategoryId != nil {
+		category, err := r.embeddedCategoryLocked(*request.CategoryId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create menu item: %w", err)
+		}
+		menuItem.CategoryId = &category.ID
+		menuItem.Category = category
+	}
+	r.db.menuItems[menuItem.ID] = menuItem
+
+	return &menuItem, nil
+}
+
+func (r *memoryMenuItemRepository) ListMenuItems(ctx context.Context, request ListMenuItemsRequest) ([]DenormalizedMenuItem, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	menuItems := []DenormalizedMenuItem{}
+	for _, menuItem := range r.db.menuItems {
+		if request.CategoryID != "" && (menuItem.CategoryId == nil || *menuItem.CategoryId != request.CategoryID) {
+			continue
+		}
+		if request.IsAvailable != nil && menuItem.IsAvailable != *request.IsAvailable {
+			continue
+		}
+		menuItems = append(menuItems, menuItem)
+	}
+	// * Sama kayak Firestore: urut berdasarkan name
+	sort.Slice(menuItems, func(i, j int) bool {
+		return menuItems[i].Name < menuItems[j].Name
+	})
+
+	return menuItems, nil
+}
+
+func (r *memoryMenuItemRepository) GetMenuItemByID(ctx context.Context, id string) (*DenormalizedMenuItem, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	menuItem, ok := r.db.menuItems[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get menu item %s: %w", id, ErrNotFound)
+	}
+
+	return &menuItem, nil
+}
+
+func (r *memoryMenuItemRepository) GetMenuItemsByIDs(ctx context.Context, ids []string) (map[string]DenormalizedMenuItem, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	menuItems := map[string]DenormalizedMenuItem{}
+	for _, id := range ids {
+		if menuItem, ok := r.db.menuItems[id]; ok {
+			menuItems[id] = menuItem
+		}
+	}
+
+	return menuItems, nil
+}
+
+func (r *memoryMenuItemRepository) UpdateMenuItem(ctx context.Context, id string, request UpdateMenuItemRequest) (*DenormalizedMenuItem, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	menuItem, ok := r.db.menuItems[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to update menu item %s: %w", id, ErrNotFound)
+	}
+	if request.Name == nil && request.Price == nil && request.ImageUrl == nil && request.CategoryId == nil && request.IsAvailable == nil {
+		return &menuItem, nil
+	}
+
+	if request.CategoryId != nil {
+		if *request.CategoryId == "" {
+			menuItem.CategoryId = nil
+			menuItem.Category = nil
+		} else {
+			category, err := r.embeddedCategoryLocked(*request.CategoryId)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update menu item %s: %w", id, err)
+			}
+			menuItem.CategoryId = &category.ID
+			menuItem.Category = category
+		}
+	}
+	if request.Name != nil {
+		menuItem.Name = *request.Name
+	}
+	if request.Price != nil {
+		menuItem.Price = *request.Price
+	}
+	if request.ImageUrl != nil {
+		menuItem.ImageUrl = request.ImageUrl
+		if *request.ImageUrl == "" {
+			menuItem.ImageUrl = nil
+		}
+	}
+	if request.IsAvailable != nil {
+		menuItem.IsAvailable = *request.IsAvailable
+	}
+	menuItem.UpdatedAt = time.Now()
+	r.db.menuItems[id] = menuItem
+
+	return &menuItem, nil
+}
+
+func (r *memoryMenuItemRepository) DeleteMenuItem(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.menuItems[id]; !ok {
+		return fmt.Errorf("failed to delete menu item %s: %w", id, ErrNotFound)
+	}
+	delete(r.db.menuItems, id)
+	return nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemoryMenuItemUnknownCategory(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	missing := "missing"
+
+	if _, err := store.MenuItems.CreateMenuItem(ctx, CreateMenuItemRequest{Name: "Es Teh", Price: 5000, CategoryId: &missing}); !errors.Is(err, ErrCategoryNotFound) {
+		t.Fatalf("CreateMenuItem error = %v, want ErrCategoryNotFound", err)
+	}
+
+	menuItem, err := store.MenuItems.CreateMenuItem(ctx, CreateMenuItemRequest{Name: "Es Teh", Price: 5000})
+	if err != nil {
+		t.Fatalf("CreateMenuItem: %v", err)
+	}
+	if _, err := store.MenuItems.UpdateMenuItem(ctx, menuItem.ID, UpdateMenuItemRequest{CategoryId: &missing}); !errors.Is(err, ErrCategoryNotFound) {
+		t.Fatalf("UpdateMenuItem error = %v, want ErrCategoryNotFound", err)
+	}
+}
+
+func TestMemoryUpdateMenuItemClearsFields(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	category, err := store.Categories.CreateCategory(ctx, CreateCategoryRequest{Name: "Minuman"})
+	if err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	imageURL := "https://example.com/es-teh.jpg"
+	menuItem, err := store.MenuItems.CreateMenuItem(ctx, CreateMenuItemRequest{Name: "Es Teh", Price: 5000, ImageUrl: &imageURL, CategoryId: &category.ID})
+	if err != nil {
+		t.Fatalf("CreateMenuItem: %v", err)
+	}
+
+	empty := ""
+	updated, err := store.MenuItems.UpdateMenuItem(ctx, menuItem.ID, UpdateMenuItemRequest{ImageUrl: &empty, CategoryId: &empty})
+	if err != nil {
+		t.Fatalf("UpdateMenuItem: %v", err)
+	}
+	if updated.ImageUrl != nil {
+		t.Errorf("ImageUrl = %q, want cleared", *updated.ImageUrl)
+	}
+	if updated.CategoryId != nil || updated.Category != nil {
+		t.Errorf("category = %v / %+v, want cleared", updated.CategoryId, updated.Category)
+	}
+
+	// * Setelah kategori dilepas, kategorinya bisa dihapus
+	if err := store.Categories.DeleteCategory(ctx, category.ID); err != nil {
+		t.Errorf("DeleteCategory after clearing: %v", err)
+	}
+}
+
+func TestMemoryDeleteMenuItemNotFound(t *testing.T) {
+	store := NewMemoryStore()
+
+	if err := store.MenuItems.DeleteMenuItem(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("DeleteMenuItem error = %v, want ErrNotFound", err)
+	}
+}
@@ -29,14 +29,15 @@ type Category struct {
 }
 
 type DenormalizedMenuItem struct {
-	ID         string    `firestore:"id"`
-	Name       string    `firestore:"name"`
-	Price      float64   `firestore:"price"`
-	ImageUrl   *string   `firestore:"imageUrl,omitempty"`
-	CategoryId *string   `firestore:"categoryId,omitempty"`
-	Category   *Category `firestore:"category,omitempty"`
-	CreatedAt  any       `firestore:"createdAt"`
-	UpdatedAt  any       `firestore:"updatedAt"`
+	ID          string    `firestore:"id"`
+	Name        string    `firestore:"name"`
+	Price       float64   `firestore:"price"`
+	ImageUrl    *string   `firestore:"imageUrl,omitempty"`
+	CategoryId  *string   `firestore:"categoryId,omitempty"`
+	Category    *Category `firestore:"category,omitempty"` // Salinan dari koleksi categories
+	IsAvailable bool      `firestore:"isAvailable"`        // Menu yang lagi habis gak bisa dipesan
+	CreatedAt   any       `firestore:"createdAt"`
+	UpdatedAt   any       `firestore:"updatedAt"`
 }
 
 type CartItem struct {
@@ -79,6 +80,12 @@ type Order struct {
 	PaymentDisplayURL   *string             `firestore:"paymentDisplayUrl,omitempty"` // Untuk URL QRIS, dll.
 	PaymentExpiry       *time.Time          `firestore:"paymentExpiry,omitempty"`     // Waktu kedaluwarsa
 	PaymentDetailsRaw   *map[string]any     `firestore:"paymentDetailsRaw,omitempty"` // Data mentah dari Midtrans
+	ReservationID       *string             `firestore:"reservationId,omitempty"`
+	ManualPayment       bool                `firestore:"manualPayment"`         // Cash / transfer manual yang dikonfirmasi admin
+	PaymentType         *string             `firestore:"paymentType,omitempty"` // payment_type yang dikirim ke Midtrans
+	BillKey             *string             `firestore:"billKey,omitempty"`     // Mandiri Bill (echannel)
+	BillerCode          *string             `firestore:"billerCode,omitempty"`  // Mandiri Bill (echannel)
+	RefundedAmount      float64             `firestore:"refundedAmount"`        // Total yang sudah di-refund
 	CreatedAt           any                 `firestore:"createdAt"`
 	UpdatedAt           any                 `firestore:"updatedAt"`
 }
@@ -87,7 +94,8 @@ type RestaurantTable struct {
 	ID          string         `firestore:"id"`
 	TableNumber string         `firestore:"tableNumber"`
 	Capacity    int            `firestore:"capacity"`
-	IsAvailable bool           `firestore:"isAvailable"`
+	IsAvailable bool           `firestore:"isAvailable"` // Saklar admin, meja bisa dipesan atau gak
+	IsOccupied  bool           `firestore:"isOccupied"`  // Ada tamu yang lagi duduk (reservasi occupied)
 	Location    enums.Location `firestore:"location"`
 	CreatedAt   any            `firestore:"createdAt"`
 	UpdatedAt   any            `firestore:"updatedAt"`
@@ -100,7 +108,8 @@ type TableReservation struct {
 	OrderID         string                  `firestore:"orderId"`
 	ReservationTime any                     `firestore:"reservationTime"`
 	Status          enums.ReservationStatus `firestore:"status"`
-	Table           *RestaurantTable        `firestore:"table,omitempty"`
+	PartySize       int                     `firestore:"partySize"`
+	Table           *RestaurantTable        `firestore:"table,omitempty"` // Salinan meja dari server saat reservasi dibuat
 	CreatedAt       any                     `firestore:"createdAt"`
 	UpdatedAt       any                     `firestore:"updatedAt"`
 }
@@ -119,3 +128,65 @@ type PaymentMethod struct {
 	CreatedAt                 any                     `firestore:"createdAt"`
 	UpdatedAt                 any                     `firestore:"updatedAt"`
 }
+
+type PaymentNotification struct {
+	ID                string     `firestore:"id"`
+	OrderID           string     `firestore:"orderId"`
+	TransactionID     string     `firestore:"transactionId"`
+	TransactionStatus string     `firestore:"transactionStatus"`
+	FraudStatus       string     `firestore:"fraudStatus"`
+	StatusCode        string     `firestore:"statusCode"`
+	GrossAmount       string     `firestore:"grossAmount"`
+	PaymentType       string     `firestore:"paymentType"`
+	MerchantID        string     `firestore:"merchantId"`
+	Result            string     `firestore:"result"`           // applied, ignored, rejected, kosong kalau belum diproses
+	Reason            string     `firestore:"reason,omitempty"` // Alasan kalau rejected
+	ProcessedAt       *time.Time `firestore:"processedAt,omitempty"`
+	CreatedAt         any        `firestore:"createdAt"`
+}
+
+// * Refund disimpan di sub-collection orders/{orderId}/refunds
+type Refund struct {
+	ID               string    `firestore:"id"`
+	OrderID          string    `firestore:"orderId"`
+	Amount           float64   `firestore:"amount"`
+	Reason           string    `firestore:"reason"`
+	ActorID          string    `firestore:"actorId"`                    // User / admin yang minta refund
+	Method           string    `firestore:"method"`                     // midtrans, midtransDirect, manual
+	RefundKey        string    `firestore:"refundKey"`                  // Idempotency key yang dikirim ke Midtrans
+	GatewayReference *string   `firestore:"gatewayReference,omitempty"` // refund_chargeback_id dari Midtrans
+	CreatedAt        time.Time `firestore:"createdAt"`
+}
+
+const (
+	PaymentEventSourceCharge       = "charge"
+	PaymentEventSourceNotification = "notification"
+)
+
+// * PaymentEvent disimpan di sub-collection orders/{orderId}/paymentEvents, isinya payload mentah Midtrans
+type PaymentEvent struct {
+	ID                string         `firestore:"id"`
+	OrderID           string         `firestore:"orderId"`
+	Source            string         `firestore:"source"` // charge, notification
+	TransactionStatus string         `firestore:"transactionStatus"`
+	Payload           map[string]any `firestore:"payload"`
+	CreatedAt         time.Time      `firestore:"createdAt"`
+}
+
+const (
+	IdempotencyStatusProcessing = "processing"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+// * IdempotencyKey nyimpen response pertama dari request POST yang bawa header Idempotency-Key
+type IdempotencyKey struct {
+	ID             string    `firestore:"id"` // sha256(userId + key), biar aman dipakai jadi document ID
+	UserID         string    `firestore:"userId"`
+	Key            string    `firestore:"key"`
+	RequestHash    string    `firestore:"requestHash"` // sha256 body request, buat deteksi key yang dipakai ulang
+	Status         string    `firestore:"status"`      // processing, completed
+	ResponseStatus int       `firestore:"responseStatus"`
+	ResponseBody   string    `firestore:"responseBody"`
+	CreatedAt      time.Time `firestore:"createdAt"`
+	ExpiresAt      time.Time `firestore:"expiresAt"` // Pasang TTL policy Firestore di field ini
+}
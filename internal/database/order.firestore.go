@@ -2,247 +2,254 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/Rizz404/midtrans-handler/internal/enums"
-	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/coreapi"
 )
 
-type CreateOrderWithPaymentRequest struct {
-	UserID              string
-	PaymentMethodID     string
-	OrderType           enums.OrderType
-	EstimatedReadyTime  *time.Time
-	SpecialInstructions *string
-	TableReservation    *CreateTableReservationRequest
-	OrderItems          []OrderItem
+type firestoreOrderRepository struct {
+	client *firestore.Client
 }
 
-type UpdateOrderRequest struct {
-	OrderStatus   *enums.OrderStatus
-	PaymentStatus *enums.PaymentStatus
+func (r *firestoreOrderRepository) NewOrderID() string {
+	return r.client.Collection("orders").NewDoc().ID
 }
 
-func CreateOrderWithPayment(
-	ctx context.Context,
-	firestoreClient *firestore.Client,
-	midtransClient *coreapi.Client,
-	req CreateOrderWithPaymentRequest,
-) (*Order, error) {
-	user, err := GetUserByID(ctx, firestoreClient, req.UserID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %v", err)
-	}
+func (r *firestoreOrderRepository) CreateOrder(ctx context.Context, request CreateOrderRequest) error {
+	order := request.Order
 
-	paymentMethod, err := GetPaymentMethodByID(ctx, firestoreClient, req.PaymentMethodID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get payment method: %v", err)
-	}
-	if paymentMethod.MidtransIdentifier == nil {
-		return nil, fmt.Errorf("midtrans identifier is null choose another: %v", err)
+	orderData := map[string]any{
+		"id":                  order.ID,
+		"userId":              order.UserID,
+		"paymentMethodId":     order.PaymentMethodID,
+		"orderType":           order.OrderType,
+		"status":              order.Status,
+		"paymentStatus":       order.PaymentStatus,
+		"totalAmount":         order.TotalAmount,
+		"orderDate":           order.OrderDate,
+		"estimatedReadyTime":  order.EstimatedReadyTime,
+		"specialInstructions": order.SpecialInstructions,
+		"orderItems":          order.OrderItems,
+		"paymentCode":         order.PaymentCode,
+		"paymentDisplayUrl":   order.PaymentDisplayURL,
+		"paymentExpiry":       order.PaymentExpiry,
+		"manualPayment":       order.ManualPayment,
+		"paymentType":         order.PaymentType,
+		"billKey":             order.BillKey,
+		"billerCode":          order.BillerCode,
+		"refundedAmount":      order.RefundedAmount,
+		"paymentDetailsRaw":   order.PaymentDetailsRaw,
+		"createdAt":           firestore.ServerTimestamp,
+		"updatedAt":           firestore.ServerTimestamp,
 	}
 
-	var totalAmount float64
-	for _, item := range req.OrderItems {
-		totalAmount += item.Total
-	}
+	// * Pakai transaksi (bukan batch) biar cek meja + reservasi yang bentrok ikut atomik dengan write-nya
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// * Firestore transaction: semua read harus sebelum write
+		var reservationData map[string]any
+		if reservation := request.TableReservation; reservation != nil {
+			table, err := getTableInTx(r.client, tx, reservation.TableID)
+			if err != nil {
+				return err
+			}
 
-	now := time.Now()
+			at := reservationTimeOf(*reservation)
+			existingDocs, err := tx.Documents(r.client.Collection("tableReservations").
+				Where("tableId", "==", reservation.TableID).
+				Where("reservationTime", ">", at.Add(-request.SeatingDuration)).
+				Where("reservationTime", "<", at.Add(request.SeatingDuration))).GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to query reservations for table %s: %v", reservation.TableID, err)
+			}
+			existing := make([]TableReservation, 0, len(existingDocs))
+			for _, doc := range existingDocs {
+				var other TableReservation
+				if err := doc.DataTo(&other); err != nil {
+					return fmt.Errorf("failed to decode table reservation %s: %v", doc.Ref.ID, err)
+				}
+				existing = append(existing, other)
+			}
 
-	orderID := firestoreClient.Collection("orders").NewDoc().ID
-	chargeReq := buildMidtransChargeRequest(orderID, totalAmount, user, paymentMethod, req.OrderItems)
+			if err := checkNewReservation(table, *reservation, request.SeatingDuration, existing); err != nil {
+				return err
+			}
 
-	chargeResp, chargeErr := midtransClient.ChargeTransaction(chargeReq)
-	if chargeErr != nil {
-		return nil, fmt.Errorf("midtrans charge failed: %v", chargeErr.GetMessage())
-	}
+			reservationData = map[string]any{
+				"id":              reservation.ID,
+				"userId":          reservation.UserID,
+				"tableId":         reservation.TableID,
+				"orderId":         reservation.OrderID,
+				"reservationTime": reservation.ReservationTime,
+				"status":          reservation.Status,
+				"partySize":       reservation.PartySize,
+				"table":           table,
+				"createdAt":       firestore.ServerTimestamp,
+				"updatedAt":       firestore.ServerTimestamp,
+			}
+		}
 
-	batch := firestoreClient.Batch()
+		var cartDocs []*firestore.DocumentSnapshot
+		if len(request.ClearCartMenuItemIDs) > 0 {
+			cartItemsQuery := r.client.Collection("cartItems").
+				Where("userId", "==", order.UserID).
+				Where("menuItemId", "in", request.ClearCartMenuItemIDs)
 
-	orderData := map[string]any{
-		"id":                  orderID,
-		"userId":              req.UserID,
-		"paymentMethodId":     req.PaymentMethodID,
-		"orderType":           req.OrderType,
-		"status":              enums.OrderStatusPending,
-		"paymentStatus":       enums.PaymentStatusPending,
-		"totalAmount":         totalAmount,
-		"orderDate":           now,
-		"estimatedReadyTime":  req.EstimatedReadyTime,
-		"specialInstructions": req.SpecialInstructions,
-		"orderItems":          req.OrderItems,
-		"paymentCode":         nil,
-		"paymentDisplayUrl":   nil,
-		"paymentExpiry":       nil,
-		// "paymentDetailsRaw":   chargeResp,
-		"createdAt": firestore.ServerTimestamp,
-		"updatedAt": firestore.ServerTimestamp,
-	}
-
-	if len(chargeResp.VaNumbers) > 0 {
-		orderData["paymentCode"] = chargeResp.VaNumbers[0].VANumber
-	}
-	if chargeResp.PaymentCode != "" {
-		orderData["paymentCode"] = chargeResp.PaymentCode
-	}
-	for _, action := range chargeResp.Actions {
-		if action.Name == "generate-qr-code" || action.Name == "deeplink-redirect" {
-			orderData["paymentDisplayUrl"] = action.URL
-			break
+			var err error
+			cartDocs, err = tx.Documents(cartItemsQuery).GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to query cart items for deletion: %v", err)
+			}
 		}
-	}
-	expiryTime, err := time.Parse("2006-01-02 15:04:05", chargeResp.ExpiryTime)
-	if err == nil {
-		orderData["paymentExpiry"] = expiryTime
-	}
-
-	if req.OrderType == enums.OrderTypeDineIn && req.TableReservation != nil {
-		reservationID := firestoreClient.Collection("tableReservations").NewDoc().ID
-		reservationData := map[string]any{
-			"id":              reservationID,
-			"userId":          req.UserID,
-			"tableId":         req.TableReservation.TableId,
-			"orderId":         orderID,
-			"reservationTime": req.TableReservation.ReservationTime,
-			"status":          enums.StatusReserved,
-			"table":           req.TableReservation.Table,
-			"createdAt":       firestore.ServerTimestamp,
-			"updatedAt":       firestore.ServerTimestamp,
-		}
-		reservationDocRef := firestoreClient.Collection("tableReservations").Doc(reservationID)
-		batch.Set(reservationDocRef, reservationData)
-		orderData["reservationId"] = reservationID
-	}
-
-	orderDocRef := firestoreClient.Collection("orders").Doc(orderID)
-	batch.Set(orderDocRef, orderData)
 
-	var menuItemIDs []string
-	for _, item := range req.OrderItems {
-		menuItemIDs = append(menuItemIDs, item.MenuItemId)
-	}
+		if reservationData != nil {
+			reservationDocRef := r.client.Collection("tableReservations").Doc(request.TableReservation.ID)
+			if err := tx.Create(reservationDocRef, reservationData); err != nil {
+				return err
+			}
+			orderData["reservationId"] = request.TableReservation.ID
+		}
 
-	if len(menuItemIDs) > 0 {
-		cartItemsQuery := firestoreClient.Collection("cartItems").
-			Where("userId", "==", req.UserID).
-			Where("menuItemId", "in", menuItemIDs)
+		if err := tx.Create(r.client.Collection("orders").Doc(order.ID), orderData); err != nil {
+			return err
+		}
 
-		docs, err := cartItemsQuery.Documents(ctx).GetAll()
-		if err != nil {
-			return nil, fmt.Errorf("failed to query cart items for deletion: %v", err)
+		if entry := request.Outbox; entry != nil {
+			if err := tx.Set(r.client.Collection("outbox").Doc(entry.ID), outboxEntryData(*entry)); err != nil {
+				return err
+			}
 		}
-		for _, doc := range docs {
-			batch.Delete(doc.Ref)
+
+		for _, doc := range cartDocs {
+			if err := tx.Delete(doc.Ref); err != nil {
+				return err
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create order %s: %w", order.ID, mapFirestoreError(err))
 	}
 
-	if _, err := batch.Commit(ctx); err != nil {
-		log.Printf("CRITICAL: Order %s created at Midtrans but failed to commit batch to Firestore: %v", orderID, err)
-		return nil, fmt.Errorf("payment created but failed to save order and related data: %v", err)
-	}
-
-	finalOrder := &Order{
-		ID:                  orderID,
-		UserID:              req.UserID,
-		PaymentMethodID:     req.PaymentMethodID,
-		OrderType:           req.OrderType,
-		Status:              enums.OrderStatusPending,
-		PaymentStatus:       enums.PaymentStatusPending,
-		TotalAmount:         totalAmount,
-		EstimatedReadyTime:  req.EstimatedReadyTime,
-		SpecialInstructions: req.SpecialInstructions,
-		OrderItems:          req.OrderItems,
-		OrderDate:           now,
-		// PaymentDetailsRaw:   toMapPointer(orderData["paymentDetailsRaw"]),
-		PaymentCode:       toStringPointer(orderData["paymentCode"]),
-		PaymentDisplayURL: toStringPointer(orderData["paymentDisplayUrl"]),
-		PaymentExpiry:     toTimePointer(orderData["paymentExpiry"]),
-		CreatedAt:         toTimePointer(orderData["createdAt"]),
-		UpdatedAt:         toTimePointer(orderData["updatedAt"]),
-	}
-
-	return finalOrder, nil
+	return nil
 }
 
-func toMapPointer(val any) *map[string]any {
-	if m, ok := val.(map[string]any); ok {
-		return &m
+// * readOrderReservationChangeInTx ngembaliin nil kalau reservasinya gak perlu diubah / sudah dihapus
+func readOrderReservationChangeInTx(client *firestore.Client, tx *firestore.Transaction, order Order, reservationID string) (*firestoreReservationChange, error) {
+	docSnapshot, err := tx.Get(client.Collection("tableReservations").Doc(reservationID))
+	if errors.Is(mapFirestoreError(err), ErrNotFound) {
+		return nil, nil
 	}
-	return nil
-}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table reservation %s: %v", reservationID, err)
+	}
+	var reservation TableReservation
+	if err := docSnapshot.DataTo(&reservation); err != nil {
+		return nil, fmt.Errorf("failed to decode table reservation %s: %v", reservationID, err)
+	}
+	reservation.ID = reservationID
 
-func toStringPointer(val any) *string {
-	if s, ok := val.(string); ok {
-		return &s
+	status, ok := reservationStatusAfterOrder(order, reservation)
+	if !ok {
+		return nil, nil
 	}
-	return nil
+	return readReservationChangeInTx(client, tx, reservation, status)
 }
 
-func toTimePointer(val any) *time.Time {
-	if t, ok := val.(time.Time); ok {
-		return &t
+// * getTableInTx ngembaliin nil (bukan error) kalau mejanya gak ada
+func getTableInTx(client *firestore.Client, tx *firestore.Transaction, tableID string) (*RestaurantTable, error) {
+	docSnapshot, err := tx.Get(client.Collection("tables").Doc(tableID))
+	if errors.Is(mapFirestoreError(err), ErrNotFound) {
+		return nil, nil
 	}
-	return nil
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table %s: %v", tableID, err)
+	}
+
+	var table RestaurantTable
+	if err := docSnapshot.DataTo(&table); err != nil {
+		return nil, fmt.Errorf("failed to decode table %s: %v", tableID, err)
+	}
+	return &table, nil
 }
 
-func buildMidtransChargeRequest(orderID string, totalAmount float64, user *User, paymentMethod *PaymentMethod, items []OrderItem) *coreapi.ChargeReq {
-	var midtransItems []midtrans.ItemDetails
-	for _, item := range items {
-		var itemName string
-		if item.MenuItem != nil {
-			itemName = item.MenuItem.Name
+func (r *firestoreOrderRepository) FinalizeOrder(ctx context.Context, request FinalizeOrderRequest) (*Order, error) {
+	order := request.Order
+	orderDocRef := r.client.Collection("orders").Doc(order.ID)
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// * Firestore transaction: semua read harus sebelum write
+		docSnapshot, err := tx.Get(orderDocRef)
+		if err != nil {
+			return mapFirestoreError(err)
+		}
+		var current Order
+		if err := docSnapshot.DataTo(&current); err != nil {
+			return fmt.Errorf("failed to decode order %s: %v", order.ID, err)
+		}
+		if current.Status != enums.OrderStatusCreating {
+			return newInvalidTransitionError("status", current.Status, enums.OrderStatusPending)
 		}
-		midtransItems = append(midtransItems, midtrans.ItemDetails{
-			ID:    item.MenuItemId,
-			Price: int64(item.Price),
-			Qty:   int32(item.Quantity),
-			Name:  itemName,
+
+		var cartDocs []*firestore.DocumentSnapshot
+		if len(request.ClearCartMenuItemIDs) > 0 {
+			cartItemsQuery := r.client.Collection("cartItems").
+				Where("userId", "==", current.UserID).
+				Where("menuItemId", "in", request.ClearCartMenuItemIDs)
+			cartDocs, err = tx.Documents(cartItemsQuery).GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to query cart items for deletion: %v", err)
+			}
+		}
+
+		err = tx.Update(orderDocRef, []firestore.Update{
+			{Path: "status", Value: enums.OrderStatusPending},
+			{Path: "paymentType", Value: order.PaymentType},
+			{Path: "paymentCode", Value: order.PaymentCode},
+			{Path: "paymentDisplayUrl", Value: order.PaymentDisplayURL},
+			{Path: "paymentExpiry", Value: order.PaymentExpiry},
+			{Path: "paymentDetailsRaw", Value: order.PaymentDetailsRaw},
+			{Path: "billKey", Value: order.BillKey},
+			{Path: "billerCode", Value: order.BillerCode},
+			{Path: "updatedAt", Value: firestore.ServerTimestamp},
 		})
-	}
-	chargeReq := &coreapi.ChargeReq{
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  orderID,
-			GrossAmt: int64(totalAmount),
-		},
-		CustomerDetails: &midtrans.CustomerDetails{
-			FName: user.Username,
-			LName: user.Username,
-			Email: user.Email,
-			Phone: user.PhoneNumber,
-		},
-		Items: &midtransItems,
-	}
-	switch paymentMethod.PaymentMethodType {
-	case enums.PaymentMethodTypeVirtualAccount:
-		chargeReq.PaymentType = coreapi.PaymentTypeBankTransfer
-		chargeReq.BankTransfer = &coreapi.BankTransferDetails{Bank: midtrans.Bank(*paymentMethod.MidtransIdentifier)}
-	case enums.PaymentMethodTypeEWallet:
-		switch *paymentMethod.MidtransIdentifier {
-		case "gopay":
-			chargeReq.PaymentType = coreapi.PaymentTypeGopay
-		case "shopeepay":
-			chargeReq.PaymentType = coreapi.PaymentTypeShopeepay
-			chargeReq.ShopeePay = &coreapi.ShopeePayDetails{CallbackUrl: "https://your-domain.com/shopeepay/callback"}
+		if err != nil {
+			return err
+		}
+
+		if event := request.ChargeEvent; event != nil {
+			if err := tx.Set(orderDocRef.Collection("paymentEvents").Doc(event.ID), paymentEventData(*event)); err != nil {
+				return err
+			}
 		}
-	case enums.PaymentMethodTypeQrCode:
-		chargeReq.PaymentType = coreapi.PaymentTypeQris
-		chargeReq.Qris = &coreapi.QrisDetails{Acquirer: *paymentMethod.MidtransIdentifier}
-	case enums.PaymentMethodTypeOverTheCounter:
-		chargeReq.PaymentType = coreapi.PaymentTypeConvenienceStore
-		chargeReq.ConvStore = &coreapi.ConvStoreDetails{Store: *paymentMethod.MidtransIdentifier}
-	}
-	return chargeReq
+
+		for _, doc := range cartDocs {
+			if err := tx.Delete(doc.Ref); err != nil {
+				return err
+			}
+		}
+
+		// * Set merge biar gak gagal kalau entry outbox-nya gak ada
+		return tx.Set(r.client.Collection("outbox").Doc(order.ID), map[string]any{
+			"status":    OutboxStatusDone,
+			"updatedAt": firestore.ServerTimestamp,
+		}, firestore.MergeAll)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to finalize order %s: %w", order.ID, err)
+	}
+
+	return r.GetOrderByID(ctx, order.ID)
 }
 
-func GetOrderByID(ctx context.Context, client *firestore.Client, id string) (*Order, error) {
-	docRef := client.Collection("orders").Doc(id)
+func (r *firestoreOrderRepository) GetOrderByID(ctx context.Context, id string) (*Order, error) {
+	docRef := r.client.Collection("orders").Doc(id)
 	docSnapshot, err := docRef.Get(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get order %s: %v", id, err)
+		return nil, fmt.Errorf("failed to get order %s: %w", id, mapFirestoreError(err))
 	}
 	var order Order
 	if err := docSnapshot.DataTo(&order); err != nil {
@@ -251,8 +258,8 @@ func GetOrderByID(ctx context.Context, client *firestore.Client, id string) (*Or
 	return &order, nil
 }
 
-func UpdateOrder(ctx context.Context, client *firestore.Client, id string, request UpdateOrderRequest) (*Order, error) {
-	docRef := client.Collection("orders").Doc(id)
+func (r *firestoreOrderRepository) UpdateOrder(ctx context.Context, id string, request UpdateOrderRequest) (*Order, error) {
+	docRef := r.client.Collection("orders").Doc(id)
 	updates := []firestore.Update{}
 	if request.OrderStatus != nil {
 		updates = append(updates, firestore.Update{Path: "status", Value: *request.OrderStatus})
@@ -260,12 +267,167 @@ func UpdateOrder(ctx context.Context, client *firestore.Client, id string, reque
 	if request.PaymentStatus != nil {
 		updates = append(updates, firestore.Update{Path: "paymentStatus", Value: *request.PaymentStatus})
 	}
+	if request.PaymentProof != nil {
+		updates = append(updates, firestore.Update{Path: "paymentProof", Value: *request.PaymentProof})
+	}
 	if len(updates) == 0 {
-		return GetOrderByID(ctx, client, id)
+		return r.GetOrderByID(ctx, id)
 	}
 	updates = append(updates, firestore.Update{Path: "updatedAt", Value: firestore.ServerTimestamp})
-	if _, err := docRef.Update(ctx, updates); err != nil {
-		return nil, fmt.Errorf("failed to update order %s: %v", id, err)
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docSnapshot, err := tx.Get(docRef)
+		if err != nil {
+			return mapFirestoreError(err)
+		}
+		var current Order
+		if err := docSnapshot.DataTo(&current); err != nil {
+			return fmt.Errorf("failed to decode order %s: %v", id, err)
+		}
+		if err := validateOrderTransition(current, request); err != nil {
+			return err
+		}
+
+		var reservationChange *firestoreReservationChange
+		if request.SyncReservation && current.ReservationID != nil {
+			next := current
+			if request.OrderStatus != nil {
+				next.Status = *request.OrderStatus
+			}
+			reservationChange, err = readOrderReservationChangeInTx(r.client, tx, next, *current.ReservationID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Update(docRef, updates); err != nil {
+			return err
+		}
+		if reservationChange != nil {
+			return reservationChange.apply(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order %s: %w", id, err)
 	}
-	return GetOrderByID(ctx, client, id)
+	return r.GetOrderByID(ctx, id)
+}
+
+func (r *firestoreOrderRepository) GetExpiredPendingOrders(ctx context.Context, before time.Time, limit int) ([]Order, error) {
+	docs, err := r.client.Collection("orders").
+		Where("paymentStatus", "==", enums.PaymentStatusPending).
+		Where("paymentExpiry", "<=", before).
+		OrderBy("paymentExpiry", firestore.Asc).
+		Limit(limit).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired orders: %v", err)
+	}
+
+	orders := make([]Order, 0, len(docs))
+	for _, doc := range docs {
+		var order Order
+		if err := doc.DataTo(&order); err != nil {
+			return nil, fmt.Errorf("failed to decode order %s: %v", doc.Ref.ID, err)
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+// * Order manual disaring di Go, bukan Where("manualPayment", "==", false), karena dokumen lama
+// * gak punya field manualPayment dan bakal ikut kesaring. Query diulang per halaman sampai limit terisi.
+func (r *firestoreOrderRepository) GetGatewayOrdersByPaymentStatus(ctx context.Context, statuses []enums.PaymentStatus, limit int) ([]Order, error) {
+	query := r.client.Collection("orders").
+		Where("paymentStatus", "in", statuses).
+		OrderBy("updatedAt", firestore.Asc).
+		Limit(limit)
+
+	orders := make([]Order, 0, limit)
+	for len(orders) < limit {
+		docs, err := query.Documents(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query orders by payment status: %v", err)
+		}
+
+		for _, doc := range docs {
+			var order Order
+			if err := doc.DataTo(&order); err != nil {
+				return nil, fmt.Errorf("failed to decode order %s: %v", doc.Ref.ID, err)
+			}
+			if !order.ManualPayment && len(orders) < limit {
+				orders = append(orders, order)
+			}
+		}
+
+		if len(docs) < limit {
+			break
+		}
+		query = query.StartAfter(docs[len(docs)-1])
+	}
+
+	return orders, nil
+}
+
+// * ListOrders butuh composite index Firestore untuk tiap kombinasi filter + orderDate
+func (r *firestoreOrderRepository) ListOrders(ctx context.Context, request ListOrdersRequest) (*ListOrdersResult, error) {
+	if err := validateListOrdersRequest(request); err != nil {
+		return nil, err
+	}
+	cursor, err := decodeOrderCursor(request)
+	if err != nil {
+		return nil, err
+	}
+	limit := normalizeListOrdersLimit(request.Limit)
+
+	query := r.client.Collection("orders").Query
+	if request.UserID != "" {
+		query = query.Where("userId", "==", request.UserID)
+	}
+	if request.Status != "" {
+		query = query.Where("status", "==", request.Status)
+	}
+	if request.PaymentStatus != "" {
+		query = query.Where("paymentStatus", "==", request.PaymentStatus)
+	}
+	if request.OrderType != "" {
+		query = query.Where("orderType", "==", request.OrderType)
+	}
+	if request.PaymentMethodID != "" {
+		query = query.Where("paymentMethodId", "==", request.PaymentMethodID)
+	}
+	if request.OrderDateFrom != nil {
+		query = query.Where("orderDate", ">=", *request.OrderDateFrom)
+	}
+	if request.OrderDateTo != nil {
+		query = query.Where("orderDate", "<", *request.OrderDateTo)
+	}
+
+	direction := firestore.Asc
+	if request.Descending {
+		direction = firestore.Desc
+	}
+	query = query.OrderBy("orderDate", direction).OrderBy(firestore.DocumentID, direction)
+	if cursor != nil {
+		query = query.StartAfter(cursor.OrderDate, cursor.ID)
+	}
+
+	docs, err := query.Limit(limit + 1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %v", err)
+	}
+
+	orders := make([]Order, 0, len(docs))
+	for _, doc := range docs {
+		var order Order
+		if err := doc.DataTo(&order); err != nil {
+			return nil, fmt.Errorf("failed to decode order %s: %v", doc.Ref.ID, err)
+		}
+		orders = append(orders, order)
+	}
+
+	return pageOrders(orders, request, limit), nil
 }
@@ -0,0 +1,407 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+)
+
+type CreateOrderWithPaymentRequest struct {
+	UserID              string
+	PaymentMethodID     string
+	OrderType           enums.OrderType
+	EstimatedReadyTime  *time.Time
+	SpecialInstructions *string
+	TableReservation    *CreateTableReservationRequest
+	OrderItems          []OrderItem
+	CardToken           *string       // Token dari Midtrans.js, wajib buat payment method card
+	SeatingDuration     time.Duration // Durasi satu reservasi, 0 = DefaultSeatingDuration
+	OpeningHours        *OpeningHours // Kalau diisi, reservasi harus di dalam jam buka
+}
+
+// * CreateOrderRequest adalah semua write yang harus masuk bareng pas order dibuat
+type CreateOrderRequest struct {
+	Order            Order
+	TableReservation *TableReservation
+	// * SeatingDuration dipakai buat cek reservasi lain yang bentrok di meja yang sama
+	SeatingDuration      time.Duration
+	ClearCartMenuItemIDs []string
+	// * Outbox diisi buat order yang masih harus di-charge ke Midtrans, ikut ditulis di batch yang sama
+	Outbox *OutboxEntry
+}
+
+// * FinalizeOrderRequest dipakai setelah charge berhasil: order creating jadi pending + field pembayaran
+type FinalizeOrderRequest struct {
+	Order                Order
+	ClearCartMenuItemIDs []string
+	ChargeEvent          *PaymentEvent
+}
+
+type UpdateOrderRequest struct {
+	OrderStatus   *enums.OrderStatus
+	PaymentStatus *enums.PaymentStatus
+	PaymentProof  *string
+	// * SyncReservation ikut ngubah reservasi meja order ini (cancelled / completed) di transaksi yang sama
+	SyncReservation bool
+	// * Precondition dicek di dalam transaksi terhadap data order terbaru, error-nya diteruskan apa adanya
+	Precondition func(current Order) error `json:"-"`
+}
+
+// * InvalidTransitionError dikembalikan UpdateOrder kalau perubahan status gak sesuai tabel transisi di enums
+type InvalidTransitionError struct {
+	Field   string
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("cannot change %s from %q to %q", e.Field, e.From, e.To)
+}
+
+func newInvalidTransitionError[S enums.Status](field string, from, to S) *InvalidTransitionError {
+	allowed := []string{}
+	for _, next := range enums.AllowedTransitions(from) {
+		allowed = append(allowed, string(next))
+	}
+	return &InvalidTransitionError{Field: field, From: string(from), To: string(to), Allowed: allowed}
+}
+
+// * validateOrderTransition dipanggil semua implementasi UpdateOrder di dalam transaksi
+func validateOrderTransition(current Order, request UpdateOrderRequest) error {
+	if request.OrderStatus != nil && !enums.CanTransition(current.Status, *request.OrderStatus) {
+		return newInvalidTransitionError("status", current.Status, *request.OrderStatus)
+	}
+	if request.PaymentStatus != nil && !enums.CanTransition(current.PaymentStatus, *request.PaymentStatus) {
+		return newInvalidTransitionError("paymentStatus", current.PaymentStatus, *request.PaymentStatus)
+	}
+	if request.Precondition != nil {
+		return request.Precondition(current)
+	}
+	return nil
+}
+
+// * ErrOrderFinalizePending: charge di Midtrans sudah jadi tapi order gagal difinalisasi.
+// * Order (status creating) tetap dikembalikan, outbox worker yang nyelesaiin, jadi jangan di-charge ulang.
+var ErrOrderFinalizePending = errors.New("payment created but order is still being finalized")
+
+func CreateOrderWithPayment(
+	ctx context.Context,
+	store *Store,
+	paymentGateway gateway.PaymentGateway,
+	req CreateOrderWithPaymentRequest,
+) (*Order, error) {
+	user, err := store.Users.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	paymentMethod, err := store.PaymentMethods.GetPaymentMethodByID(ctx, req.PaymentMethodID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment method: %v", err)
+	}
+
+	orderID := store.Orders.NewOrderID()
+
+	orderItems, totalAmount, err := priceOrderItems(ctx, store.MenuItems, orderID, req.OrderItems)
+	if err != nil {
+		return nil, err
+	}
+
+	if reason := paymentMethod.UnavailableReason(totalAmount); reason != "" {
+		return nil, fmt.Errorf("%w: payment method %s cannot be used for %v: %s", ErrInvalidOrder, paymentMethod.Name, totalAmount, reason)
+	}
+
+	now := time.Now()
+
+	order := Order{
+		ID:                  orderID,
+		UserID:              req.UserID,
+		PaymentMethodID:     req.PaymentMethodID,
+		OrderType:           req.OrderType,
+		Status:              enums.OrderStatusPending,
+		PaymentStatus:       enums.PaymentStatusPending,
+		TotalAmount:         totalAmount,
+		OrderDate:           now,
+		EstimatedReadyTime:  req.EstimatedReadyTime,
+		SpecialInstructions: req.SpecialInstructions,
+		OrderItems:          orderItems,
+	}
+
+	createReq := CreateOrderRequest{SeatingDuration: req.SeatingDuration}
+	if createReq.SeatingDuration <= 0 {
+		createReq.SeatingDuration = DefaultSeatingDuration
+	}
+
+	if req.OrderType == enums.OrderTypeDineIn && req.TableReservation != nil {
+		if err := validateTableReservationRequest(*req.TableReservation, now); err != nil {
+			return nil, err
+		}
+		if req.OpeningHours != nil && !req.OpeningHours.Allows(req.TableReservation.ReservationTime, createReq.SeatingDuration) {
+			return nil, fmt.Errorf("%w: tableReservation.reservationTime is outside opening hours", ErrInvalidOrder)
+		}
+		reservationID := store.TableReservations.NewTableReservationID()
+		createReq.TableReservation = &TableReservation{
+			ID:              reservationID,
+			UserID:          req.UserID,
+			TableID:         req.TableReservation.TableId,
+			OrderID:         orderID,
+			ReservationTime: req.TableReservation.ReservationTime,
+			Status:          enums.StatusReserved,
+			PartySize:       req.TableReservation.PartySize,
+		}
+		order.ReservationID = &reservationID
+	}
+
+	if paymentMethod.IsManual() {
+		// * Gak ada charge ke Midtrans, customer bayar pakai kode / QR admin lalu admin yang konfirmasi
+		order.ManualPayment = true
+		order.PaymentCode = paymentMethod.AdminPaymentCode
+		order.PaymentDisplayURL = paymentMethod.AdminPaymentQrCodePicture
+
+		createReq.Order = order
+		createReq.ClearCartMenuItemIDs = orderMenuItemIDs(orderItems)
+		if err := saveNewOrder(ctx, store, createReq, req.OpeningHours); err != nil {
+			return nil, err
+		}
+		return &order, nil
+	}
+
+	chargeReq, err := buildMidtransChargeRequest(orderID, totalAmount, user, paymentMethod, orderItems, req.CardToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// * Disimpan buat dicocokin dengan payment_type di webhook
+	paymentType := string(chargeReq.PaymentType)
+	order.PaymentType = &paymentType
+
+	// * Order + entry outbox ditulis dulu dengan status creating, baru charge ke Midtrans.
+	// * Kalau prosesnya putus di tengah, outbox worker yang nyelesaiin atau ngebatalin.
+	order.Status = enums.OrderStatusCreating
+	createReq.Order = order
+	createReq.Outbox = &OutboxEntry{
+		ID:      orderID,
+		OrderID: orderID,
+		Type:    OutboxTypeOrderCharge,
+		Status:  OutboxStatusPending,
+	}
+	if err := saveNewOrder(ctx, store, createReq, req.OpeningHours); err != nil {
+		return nil, err
+	}
+
+	chargeResp, chargeErr := paymentGateway.ChargeTransaction(chargeReq)
+	if chargeErr != nil {
+		if err := compensateOrderCreation(ctx, store, paymentGateway, orderID); err != nil {
+			log.Printf("OUTBOX: Failed to compensate order %s, worker will retry: %v", orderID, err)
+		}
+		return nil, fmt.Errorf("midtrans charge failed: %v", chargeErr.GetMessage())
+	}
+
+	// * Response lengkap (VA semua bank, acquirer, dll) buat dilihat support
+	rawCharge := RawPayload(chargeResp)
+	finalizedOrder, err := finalizeChargedOrder(ctx, store, order, paymentMethod, rawCharge)
+	if err != nil {
+		recordOutboxFailure(ctx, store, orderID, rawCharge, err)
+		return &order, fmt.Errorf("%w, it will be retried: %v", ErrOrderFinalizePending, err)
+	}
+
+	return finalizedOrder, nil
+}
+
+func validateTableReservationRequest(req CreateTableReservationRequest, now time.Time) error {
+	if req.TableId == "" {
+		return fmt.Errorf("%w: tableReservation.tableId is required", ErrInvalidOrder)
+	}
+	if req.PartySize <= 0 {
+		return fmt.Errorf("%w: tableReservation.partySize must be positive", ErrInvalidOrder)
+	}
+	if !req.ReservationTime.After(now) {
+		return fmt.Errorf("%w: tableReservation.reservationTime must be in the future", ErrInvalidOrder)
+	}
+	return nil
+}
+
+// * saveNewOrder manggil CreateOrder dan ngelengkapin error bentrok reservasi dengan alternatif meja / jam
+func saveNewOrder(ctx context.Context, store *Store, createReq CreateOrderRequest, openingHours *OpeningHours) error {
+	err := store.Orders.CreateOrder(ctx, createReq)
+	if err == nil {
+		return nil
+	}
+
+	var conflictErr *ReservationConflictError
+	if errors.As(err, &conflictErr) {
+		reservation := createReq.TableReservation
+		alternatives, altErr := FindReservationAlternatives(ctx, store, reservation.TableID, reservation.PartySize, conflictErr.ReservationTime, createReq.SeatingDuration, openingHours, maxReservationAlternatives)
+		if altErr != nil {
+			log.Printf("RESERVATION: Failed to find alternatives for table %s: %v", reservation.TableID, altErr)
+		}
+		conflictErr.Alternatives = alternatives
+		return conflictErr
+	}
+
+	return fmt.Errorf("failed to save order and related data: %w", err)
+}
+
+func orderMenuItemIDs(items []OrderItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.MenuItemId)
+	}
+	return ids
+}
+
+// * RawPayload ngubah struct response Midtrans jadi map sesuai tag json-nya, biar bisa disimpan apa adanya.
+// * Field kosong dibuang karena struct coreapi gak pakai omitempty.
+func RawPayload(val any) map[string]any {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return nil
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	for key, value := range m {
+		if value == nil || value == "" {
+			delete(m, key)
+		}
+	}
+	return m
+}
+
+func buildMidtransChargeRequest(orderID string, totalAmount float64, user *User, paymentMethod *PaymentMethod, items []OrderItem, cardToken *string) (*coreapi.ChargeReq, error) {
+	var midtransItems []midtrans.ItemDetails
+	var grossAmount int64
+	for _, item := range items {
+		var itemName string
+		if item.MenuItem != nil {
+			itemName = item.MenuItem.Name
+		}
+		midtransItems = append(midtransItems, midtrans.ItemDetails{
+			ID:    item.MenuItemId,
+			Price: int64(item.Price),
+			Qty:   int32(item.Quantity),
+			Name:  itemName,
+		})
+		grossAmount += int64(item.Price) * int64(item.Quantity)
+	}
+	// * Midtrans nolak charge kalau gross_amount beda sama jumlah item_details
+	if grossAmount != int64(totalAmount) {
+		return nil, fmt.Errorf("gross amount %d does not match order total %v", grossAmount, totalAmount)
+	}
+	chargeReq := &coreapi.ChargeReq{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  orderID,
+			GrossAmt: grossAmount,
+		},
+		CustomerDetails: &midtrans.CustomerDetails{
+			FName: user.Username,
+			LName: user.Username,
+			Email: user.Email,
+			Phone: user.PhoneNumber,
+		},
+		Items: &midtransItems,
+	}
+	identifier := *paymentMethod.MidtransIdentifier
+	switch paymentMethod.PaymentMethodType {
+	case enums.PaymentMethodTypeVirtualAccount:
+		chargeReq.PaymentType = coreapi.PaymentTypeBankTransfer
+		chargeReq.BankTransfer = &coreapi.BankTransferDetails{Bank: midtrans.Bank(identifier)}
+	case enums.PaymentMethodTypeEWallet:
+		switch identifier {
+		case "gopay":
+			chargeReq.PaymentType = coreapi.PaymentTypeGopay
+		case "shopeepay":
+			chargeReq.PaymentType = coreapi.PaymentTypeShopeepay
+			chargeReq.ShopeePay = &coreapi.ShopeePayDetails{CallbackUrl: "https://your-domain.com/shopeepay/callback"}
+		default:
+			return nil, fmt.Errorf("unsupported e-wallet midtrans identifier %q", identifier)
+		}
+	case enums.PaymentMethodTypeQrCode:
+		chargeReq.PaymentType = coreapi.PaymentTypeQris
+		chargeReq.Qris = &coreapi.QrisDetails{Acquirer: identifier}
+	case enums.PaymentMethodTypeOverTheCounter:
+		chargeReq.PaymentType = coreapi.PaymentTypeConvenienceStore
+		chargeReq.ConvStore = &coreapi.ConvStoreDetails{Store: identifier}
+	case enums.PaymentMethodTypeCard:
+		if cardToken == nil || *cardToken == "" {
+			return nil, fmt.Errorf("%w: cardToken is required for card payments", ErrInvalidOrder)
+		}
+		// * Selalu pakai 3DS, redirect URL-nya dikembalikan lewat PaymentDisplayURL
+		chargeReq.PaymentType = coreapi.PaymentTypeCreditCard
+		chargeReq.CreditCard = &coreapi.CreditCardDetails{TokenID: *cardToken, Authentication: true}
+		// * Identifier card boleh diisi acquiring bank (bca, bni, mandiri, dll.)
+		if identifier != string(coreapi.PaymentTypeCreditCard) {
+			chargeReq.CreditCard.Bank = identifier
+		}
+	case enums.PaymentMethodTypeEchannel:
+		chargeReq.PaymentType = coreapi.PaymentTypeEChannel
+		chargeReq.EChannel = &coreapi.EChannelDetail{BillInfo1: "Payment:", BillInfo2: "Order " + orderID}
+	case enums.PaymentMethodTypeDirectDebit:
+		switch identifier {
+		case string(coreapi.PaymentTypeBCAKlikpay):
+			chargeReq.PaymentType = coreapi.PaymentTypeBCAKlikpay
+			chargeReq.BCAKlikPay = &coreapi.BCAKlikPayDetails{Desc: "Order " + orderID}
+		case string(coreapi.PaymentTypeCimbClicks):
+			chargeReq.PaymentType = coreapi.PaymentTypeCimbClicks
+			chargeReq.CIMBClicks = &coreapi.CIMBClicksDetails{Desc: "Order " + orderID}
+		default:
+			return nil, fmt.Errorf("unsupported direct debit midtrans identifier %q", identifier)
+		}
+	default:
+		return nil, fmt.Errorf("payment method type %q cannot be charged through midtrans", paymentMethod.PaymentMethodType)
+	}
+	return chargeReq, nil
+}
+
+// * applyChargeResponse mindahin info pembayaran dari response charge ke order,
+// * field yang relevan beda-beda per tipe payment method
+func applyChargeResponse(order *Order, paymentMethod *PaymentMethod, chargeResp *coreapi.ChargeResponse) {
+	switch paymentMethod.PaymentMethodType {
+	case enums.PaymentMethodTypeVirtualAccount:
+		if len(chargeResp.VaNumbers) > 0 {
+			order.PaymentCode = &chargeResp.VaNumbers[0].VANumber
+		} else if chargeResp.PermataVaNumber != "" {
+			order.PaymentCode = &chargeResp.PermataVaNumber
+		}
+	case enums.PaymentMethodTypeOverTheCounter:
+		if chargeResp.PaymentCode != "" {
+			order.PaymentCode = &chargeResp.PaymentCode
+		}
+	case enums.PaymentMethodTypeEWallet, enums.PaymentMethodTypeQrCode:
+		for _, action := range chargeResp.Actions {
+			if action.Name == "generate-qr-code" || action.Name == "deeplink-redirect" {
+				order.PaymentDisplayURL = &action.URL
+				break
+			}
+		}
+	case enums.PaymentMethodTypeEchannel:
+		if chargeResp.BillKey != "" {
+			order.BillKey = &chargeResp.BillKey
+			order.PaymentCode = &chargeResp.BillKey
+		}
+		if chargeResp.BillerCode != "" {
+			order.BillerCode = &chargeResp.BillerCode
+		}
+	case enums.PaymentMethodTypeCard, enums.PaymentMethodTypeDirectDebit:
+		// * 3DS (card) dan halaman internet banking (direct debit) dua-duanya lewat redirect_url
+		if chargeResp.RedirectURL != "" {
+			order.PaymentDisplayURL = &chargeResp.RedirectURL
+		}
+	}
+
+	// * expiry_time dari Midtrans gak ada zona waktunya, isinya WIB
+	expiryTime, err := time.ParseInLocation(gateway.TimeLayout, chargeResp.ExpiryTime, gateway.WIB)
+	if err == nil {
+		order.PaymentExpiry = &expiryTime
+	}
+}
@@ -0,0 +1,261 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+type memoryOrderRepository struct {
+	db *MemoryDB
+}
+
+func (r *memoryOrderRepository) NewOrderID() string {
+	return newMemoryID()
+}
+
+func (r *memoryOrderRepository) CreateOrder(ctx context.Context, request CreateOrderRequest) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	order := copyOrder(request.Order)
+	if _, exists := r.db.orders[order.ID]; exists {
+		return fmt.Errorf("failed to create order %s: %w", order.ID, ErrAlreadyExists)
+	}
+
+	if reservation := request.TableReservation; reservation != nil {
+		var table *RestaurantTable
+		if stored, ok := r.db.tables[reservation.TableID]; ok {
+			table = &stored
+		}
+		existing := make([]TableReservation, 0, len(r.db.tableReservations))
+		for _, other := range r.db.tableReservations {
+			existing = append(existing, other)
+		}
+		if err := checkNewReservation(table, *reservation, request.SeatingDuration, existing); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	order.CreatedAt = now
+	order.UpdatedAt = now
+
+	if reservation := request.TableReservation; reservation != nil {
+		stored := *reservation
+		table := r.db.tables[reservation.TableID]
+		stored.Table = &table
+		stored.CreatedAt = now
+		stored.UpdatedAt = now
+		r.db.tableReservations[stored.ID] = stored
+		order.ReservationID = &stored.ID
+	}
+
+	r.db.orders[order.ID] = order
+
+	if entry := request.Outbox; entry != nil {
+		stored := *entry
+		stored.CreatedAt = now
+		stored.UpdatedAt = now
+		r.db.outbox[stored.ID] = stored
+	}
+
+	r.db.clearCartItemsLocked(order.UserID, request.ClearCartMenuItemIDs)
+
+	return nil
+}
+
+func (r *memoryOrderRepository) FinalizeOrder(ctx context.Context, request FinalizeOrderRequest) (*Order, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	id := request.Order.ID
+	order, ok := r.db.orders[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to finalize order %s: %w", id, ErrNotFound)
+	}
+	if order.Status != enums.OrderStatusCreating {
+		return nil, fmt.Errorf("failed to finalize order %s: %w", id, newInvalidTransitionError("status", order.Status, enums.OrderStatusPending))
+	}
+
+	now := time.Now()
+	order.Status = enums.OrderStatusPending
+	order.PaymentType = request.Order.PaymentType
+	order.PaymentCode = request.Order.PaymentCode
+	order.PaymentDisplayURL = request.Order.PaymentDisplayURL
+	order.PaymentExpiry = request.Order.PaymentExpiry
+	order.PaymentDetailsRaw = request.Order.PaymentDetailsRaw
+	order.BillKey = request.Order.BillKey
+	order.BillerCode = request.Order.BillerCode
+	order.UpdatedAt = now
+	r.db.orders[id] = order
+
+	if event := request.ChargeEvent; event != nil {
+		stored := *event
+		stored.CreatedAt = now
+		r.db.putPaymentEvent(stored)
+	}
+
+	r.db.clearCartItemsLocked(order.UserID, request.ClearCartMenuItemIDs)
+
+	if entry, ok := r.db.outbox[id]; ok {
+		entry.Status = OutboxStatusDone
+		entry.UpdatedAt = now
+		r.db.outbox[id] = entry
+	}
+
+	order = copyOrder(order)
+	return &order, nil
+}
+
+func (r *memoryOrderRepository) GetOrderByID(ctx context.Context, id string) (*Order, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	order, ok := r.db.orders[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get order %s: %w", id, ErrNotFound)
+	}
+	order = copyOrder(order)
+	return &order, nil
+}
+
+func (r *memoryOrderRepository) UpdateOrder(ctx context.Context, id string, request UpdateOrderRequest) (*Order, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	order, ok := r.db.orders[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to update order %s: %w", id, ErrNotFound)
+	}
+	if request.OrderStatus == nil && request.PaymentStatus == nil && request.PaymentProof == nil {
+		order = copyOrder(order)
+		return &order, nil
+	}
+	if err := validateOrderTransition(copyOrder(order), request); err != nil {
+		return nil, fmt.Errorf("failed to update order %s: %w", id, err)
+	}
+
+	if request.OrderStatus != nil {
+		order.Status = *request.OrderStatus
+	}
+	if request.PaymentStatus != nil {
+		order.PaymentStatus = *request.PaymentStatus
+	}
+	if request.PaymentProof != nil {
+		order.PaymentProof = request.PaymentProof
+	}
+	order.UpdatedAt = time.Now()
+	r.db.orders[id] = order
+
+	if request.SyncReservation && order.ReservationID != nil {
+		r.db.syncReservationLocked(order, *order.ReservationID)
+	}
+
+	order = copyOrder(order)
+	return &order, nil
+}
+
+func (r *memoryOrderRepository) GetExpiredPendingOrders(ctx context.Context, before time.Time, limit int) ([]Order, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var orders []Order
+	for _, order := range r.db.orders {
+		if order.PaymentStatus == enums.PaymentStatusPending && order.PaymentExpiry != nil && !order.PaymentExpiry.After(before) {
+			orders = append(orders, copyOrder(order))
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].PaymentExpiry.Before(*orders[j].PaymentExpiry)
+	})
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+
+	return orders, nil
+}
+
+func (r *memoryOrderRepository) GetGatewayOrdersByPaymentStatus(ctx context.Context, statuses []enums.PaymentStatus, limit int) ([]Order, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var orders []Order
+	for _, order := range r.db.orders {
+		if !order.ManualPayment && slices.Contains(statuses, order.PaymentStatus) {
+			orders = append(orders, copyOrder(order))
+		}
+	}
+	// * Sama kayak Firestore: yang paling lama gak di-update duluan
+	sort.Slice(orders, func(i, j int) bool {
+		return memoryTime(orders[i].UpdatedAt).Before(memoryTime(orders[j].UpdatedAt))
+	})
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+
+	return orders, nil
+}
+
+// * memoryTime buat field createdAt/updatedAt yang tipenya any
+func memoryTime(value any) time.Time {
+	t, _ := value.(time.Time)
+	return t
+}
+
+// * copyOrder biar caller gak bisa ngubah data di store lewat slice yang sama
+func copyOrder(order Order) Order {
+	order.OrderItems = slices.Clone(order.OrderItems)
+	return order
+}
+
+func (r *memoryOrderRepository) ListOrders(ctx context.Context, request ListOrdersRequest) (*ListOrdersResult, error) {
+	if err := validateListOrdersRequest(request); err != nil {
+		return nil, err
+	}
+	cursor, err := decodeOrderCursor(request)
+	if err != nil {
+		return nil, err
+	}
+	limit := normalizeListOrdersLimit(request.Limit)
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	// * less true kalau a muncul sebelum b di urutan yang diminta
+	less := func(a, b orderCursor) bool {
+		if !a.OrderDate.Equal(b.OrderDate) {
+			return a.OrderDate.Before(b.OrderDate) != request.Descending
+		}
+		if a.ID == b.ID {
+			return false
+		}
+		return (a.ID < b.ID) != request.Descending
+	}
+
+	var orders []Order
+	for _, order := range r.db.orders {
+		if !request.matches(order) {
+			continue
+		}
+		if cursor != nil && !less(*cursor, orderCursor{OrderDate: order.OrderDate, ID: order.ID}) {
+			continue
+		}
+		orders = append(orders, copyOrder(order))
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return less(
+			orderCursor{OrderDate: orders[i].OrderDate, ID: orders[i].ID},
+			orderCursor{OrderDate: orders[j].OrderDate, ID: orders[j].ID},
+		)
+	})
+	if len(orders) > limit+1 {
+		orders = orders[:limit+1]
+	}
+
+	return pageOrders(orders, request, limit), nil
+}
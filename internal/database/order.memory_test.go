@@ -0,0 +1,349 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+// * newTestOrderDB nyiapin satu meja dan dua cart item milik u1
+func newTestOrderDB(t *testing.T) (*MemoryDB, *Store, *RestaurantTable) {
+	t.Helper()
+	db := NewMemoryDB()
+	store := db.Store()
+	table, err := store.Tables.CreateRestaurantTable(context.Background(), CreateRestaurantTableRequest{
+		TableNumber: "T1",
+		Capacity:    4,
+		IsAvailable: true,
+		Location:    enums.LocationIndoor,
+	})
+	if err != nil {
+		t.Fatalf("CreateRestaurantTable: %v", err)
+	}
+	db.PutCartItem(CartItem{ID: "c1", UserId: "u1", MenuItemId: "m1", Quantity: 1})
+	db.PutCartItem(CartItem{ID: "c2", UserId: "u1", MenuItemId: "m2", Quantity: 1})
+	return db, store, table
+}
+
+func testCreateOrderRequest(orderID, tableID string, at time.Time) CreateOrderRequest {
+	return CreateOrderRequest{
+		Order: Order{
+			ID:            orderID,
+			UserID:        "u1",
+			Status:        enums.OrderStatusCreating,
+			PaymentStatus: enums.PaymentStatusPending,
+			TotalAmount:   10000,
+		},
+		TableReservation: &TableReservation{
+			ID:              "r-" + orderID,
+			UserID:          "u1",
+			TableID:         tableID,
+			OrderID:         orderID,
+			ReservationTime: at,
+			Status:          enums.StatusReserved,
+			PartySize:       2,
+		},
+		SeatingDuration:      time.Hour,
+		ClearCartMenuItemIDs: []string{"m1"},
+		Outbox:               &OutboxEntry{ID: orderID, OrderID: orderID, Status: OutboxStatusPending},
+	}
+}
+
+func TestMemoryCreateOrderWritesWholeBatch(t *testing.T) {
+	db, store, table := newTestOrderDB(t)
+	ctx := context.Background()
+	at := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := store.Orders.CreateOrder(ctx, testCreateOrderRequest("o1", table.ID, at)); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	order, err := store.Orders.GetOrderByID(ctx, "o1")
+	if err != nil {
+		t.Fatalf("GetOrderByID: %v", err)
+	}
+	if order.ReservationID == nil || *order.ReservationID != "r-o1" {
+		t.Errorf("ReservationID = %v, want r-o1", order.ReservationID)
+	}
+	if _, err := store.TableReservations.GetTableReservationByID(ctx, "r-o1"); err != nil {
+		t.Errorf("reservation not written: %v", err)
+	}
+	if _, err := store.Outbox.GetOutboxEntryByID(ctx, "o1"); err != nil {
+		t.Errorf("outbox entry not written: %v", err)
+	}
+	if _, ok := db.cartItems["c1"]; ok {
+		t.Error("cart item c1 should be cleared")
+	}
+	if _, ok := db.cartItems["c2"]; !ok {
+		t.Error("cart item c2 is not part of the order and should be kept")
+	}
+}
+
+func TestMemoryCreateOrderDuplicate(t *testing.T) {
+	db, store, table := newTestOrderDB(t)
+	ctx := context.Background()
+	at := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	first := testCreateOrderRequest("o1", table.ID, at)
+	first.ClearCartMenuItemIDs = nil
+	if err := store.Orders.CreateOrder(ctx, first); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	// * Reservasinya di jam lain biar yang gagal beneran karena ID order
+	duplicate := testCreateOrderRequest("o1", table.ID, at.Add(5*time.Hour))
+	duplicate.TableReservation.ID = "r-other"
+	duplicate.Order.TotalAmount = 99999
+	err := store.Orders.CreateOrder(ctx, duplicate)
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("CreateOrder duplicate error = %v, want ErrAlreadyExists", err)
+	}
+
+	order, _ := store.Orders.GetOrderByID(ctx, "o1")
+	if order.TotalAmount != 10000 {
+		t.Errorf("TotalAmount = %v, duplicate create must not overwrite the order", order.TotalAmount)
+	}
+	if _, err := store.TableReservations.GetTableReservationByID(ctx, "r-other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("reservation of the failed batch was written (err = %v)", err)
+	}
+	if _, ok := db.cartItems["c1"]; !ok {
+		t.Error("failed batch must not clear the cart")
+	}
+}
+
+func TestMemoryCreateOrderRollsBackOnReservationConflict(t *testing.T) {
+	db, store, table := newTestOrderDB(t)
+	ctx := context.Background()
+	at := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	first := testCreateOrderRequest("o1", table.ID, at)
+	first.ClearCartMenuItemIDs = nil
+	if err := store.Orders.CreateOrder(ctx, first); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	err := store.Orders.CreateOrder(ctx, testCreateOrderRequest("o2", table.ID, at.Add(30*time.Minute)))
+	var conflictErr *ReservationConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("CreateOrder error = %v, want ReservationConflictError", err)
+	}
+
+	if _, err := store.Orders.GetOrderByID(ctx, "o2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("order of the failed batch was written (err = %v)", err)
+	}
+	if _, err := store.TableReservations.GetTableReservationByID(ctx, "r-o2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("reservation of the failed batch was written (err = %v)", err)
+	}
+	if _, err := store.Outbox.GetOutboxEntryByID(ctx, "o2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("outbox entry of the failed batch was written (err = %v)", err)
+	}
+	if _, ok := db.cartItems["c1"]; !ok {
+		t.Error("failed batch must not clear the cart")
+	}
+}
+
+func TestMemoryFinalizeOrder(t *testing.T) {
+	db, store, table := newTestOrderDB(t)
+	ctx := context.Background()
+
+	request := testCreateOrderRequest("o1", table.ID, time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC))
+	request.ClearCartMenuItemIDs = nil
+	if err := store.Orders.CreateOrder(ctx, request); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	paymentCode := "1234567890"
+	finalize := FinalizeOrderRequest{
+		Order:                Order{ID: "o1", PaymentCode: &paymentCode},
+		ClearCartMenuItemIDs: []string{"m1"},
+		ChargeEvent:          &PaymentEvent{ID: "charge", OrderID: "o1", Source: "charge", TransactionStatus: "pending"},
+	}
+	order, err := store.Orders.FinalizeOrder(ctx, finalize)
+	if err != nil {
+		t.Fatalf("FinalizeOrder: %v", err)
+	}
+	if order.Status != enums.OrderStatusPending || order.PaymentCode == nil || *order.PaymentCode != paymentCode {
+		t.Errorf("order = %s / %v, want pending with payment code", order.Status, order.PaymentCode)
+	}
+	if entry, _ := store.Outbox.GetOutboxEntryByID(ctx, "o1"); entry == nil || entry.Status != OutboxStatusDone {
+		t.Errorf("outbox entry = %+v, want done", entry)
+	}
+	if events, _ := store.PaymentEvents.GetPaymentEventsByOrderID(ctx, "o1"); len(events) != 1 {
+		t.Errorf("got %d payment events, want 1", len(events))
+	}
+	if _, ok := db.cartItems["c1"]; ok {
+		t.Error("cart item c1 should be cleared")
+	}
+
+	// * Finalize kedua ditolak dan gak nyatat event lagi
+	finalize.ChargeEvent = &PaymentEvent{ID: "charge-2", OrderID: "o1", Source: "charge"}
+	var transitionErr *InvalidTransitionError
+	if _, err := store.Orders.FinalizeOrder(ctx, finalize); !errors.As(err, &transitionErr) {
+		t.Fatalf("second FinalizeOrder error = %v, want InvalidTransitionError", err)
+	}
+	if events, _ := store.PaymentEvents.GetPaymentEventsByOrderID(ctx, "o1"); len(events) != 1 {
+		t.Errorf("got %d payment events after rejected finalize, want 1", len(events))
+	}
+}
+
+func TestMemoryUpdateOrderIsAllOrNothing(t *testing.T) {
+	_, store, _ := newTestOrderDB(t)
+	ctx := context.Background()
+
+	request := CreateOrderRequest{Order: Order{
+		ID:            "o1",
+		UserID:        "u1",
+		Status:        enums.OrderStatusPending,
+		PaymentStatus: enums.PaymentStatusPending,
+	}}
+	if err := store.Orders.CreateOrder(ctx, request); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	confirmed := enums.OrderStatusConfirmed
+	refunded := enums.PaymentStatusRefunded
+	proof := "https://example.com/proof.jpg"
+	errPrecondition := errors.New("precondition failed")
+
+	tests := []struct {
+		name    string
+		request UpdateOrderRequest
+		check   func(error) bool
+	}{
+		{
+			name:    "invalid payment transition",
+			request: UpdateOrderRequest{OrderStatus: &confirmed, PaymentStatus: &refunded},
+			check: func(err error) bool {
+				var transitionErr *InvalidTransitionError
+				return errors.As(err, &transitionErr) && transitionErr.Field == "paymentStatus"
+			},
+		},
+		{
+			name: "failed precondition",
+			request: UpdateOrderRequest{
+				OrderStatus:  &confirmed,
+				PaymentProof: &proof,
+				Precondition: func(Order) error { return errPrecondition },
+			},
+			check: func(err error) bool { return errors.Is(err, errPrecondition) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.Orders.UpdateOrder(ctx, "o1", tt.request)
+			if !tt.check(err) {
+				t.Fatalf("UpdateOrder error = %v", err)
+			}
+
+			order, _ := store.Orders.GetOrderByID(ctx, "o1")
+			if order.Status != enums.OrderStatusPending || order.PaymentStatus != enums.PaymentStatusPending || order.PaymentProof != nil {
+				t.Errorf("order changed to %s / %s / %v, failed update must not apply any field", order.Status, order.PaymentStatus, order.PaymentProof)
+			}
+		})
+	}
+}
+
+func TestMemoryUpdateOrderSyncsReservation(t *testing.T) {
+	tests := []struct {
+		name            string
+		orderStatus     enums.OrderStatus
+		syncReservation bool
+		want            enums.ReservationStatus
+	}{
+		{name: "cancelled", orderStatus: enums.OrderStatusCancelled, syncReservation: true, want: enums.StatusCancelled},
+		{name: "kitchen status keeps reservation", orderStatus: enums.OrderStatusConfirmed, syncReservation: true, want: enums.StatusOccupied},
+		{name: "without sync", orderStatus: enums.OrderStatusCancelled, want: enums.StatusOccupied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, store, table := newTestOrderDB(t)
+			ctx := context.Background()
+
+			request := testCreateOrderRequest("o1", table.ID, time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC))
+			request.Order.Status = enums.OrderStatusPending
+			if err := store.Orders.CreateOrder(ctx, request); err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+			if _, err := store.TableReservations.UpdateTableReservationStatus(ctx, "r-o1", enums.StatusOccupied); err != nil {
+				t.Fatalf("UpdateTableReservationStatus: %v", err)
+			}
+
+			_, err := store.Orders.UpdateOrder(ctx, "o1", UpdateOrderRequest{OrderStatus: &tt.orderStatus, SyncReservation: tt.syncReservation})
+			if err != nil {
+				t.Fatalf("UpdateOrder: %v", err)
+			}
+
+			reservation, _ := store.TableReservations.GetTableReservationByID(ctx, "r-o1")
+			if reservation.Status != tt.want {
+				t.Errorf("reservation status = %s, want %s", reservation.Status, tt.want)
+			}
+			updatedTable, _ := store.Tables.GetRestaurantTableByID(ctx, table.ID)
+			if updatedTable.IsOccupied != (tt.want == enums.StatusOccupied) {
+				t.Errorf("IsOccupied = %v with reservation %s", updatedTable.IsOccupied, tt.want)
+			}
+			if !updatedTable.IsAvailable {
+				t.Error("IsAvailable is the admin switch and must not change")
+			}
+		})
+	}
+}
+
+func TestMemoryListOrdersCursor(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	start := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"o1", "o2", "o3"} {
+		err := store.Orders.CreateOrder(ctx, CreateOrderRequest{Order: Order{
+			ID:            id,
+			UserID:        "u1",
+			Status:        enums.OrderStatusPending,
+			PaymentStatus: enums.PaymentStatusPending,
+			OrderDate:     start.Add(time.Duration(i) * time.Hour),
+		}})
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+	}
+
+	first, err := store.Orders.ListOrders(ctx, ListOrdersRequest{UserID: "u1", Limit: 2})
+	if err != nil {
+		t.Fatalf("ListOrders: %v", err)
+	}
+	if len(first.Orders) != 2 || first.NextCursor == "" {
+		t.Fatalf("first page = %d orders, cursor %q, want 2 and a cursor", len(first.Orders), first.NextCursor)
+	}
+
+	next, err := store.Orders.ListOrders(ctx, ListOrdersRequest{UserID: "u1", Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("ListOrders next page: %v", err)
+	}
+	if len(next.Orders) != 1 || next.Orders[0].ID != "o3" || next.NextCursor != "" {
+		t.Errorf("next page = %+v, want only o3 without a cursor", next)
+	}
+
+	tests := []struct {
+		name    string
+		request ListOrdersRequest
+	}{
+		{name: "other sort direction", request: ListOrdersRequest{UserID: "u1", Descending: true}},
+		{name: "other user", request: ListOrdersRequest{UserID: "u2"}},
+		{name: "extra filter", request: ListOrdersRequest{UserID: "u1", Status: enums.OrderStatusPending}},
+		{name: "other date range", request: ListOrdersRequest{UserID: "u1", OrderDateFrom: &start}},
+		{name: "garbage", request: ListOrdersRequest{UserID: "u1", Cursor: "not-a-cursor"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.request.Cursor == "" {
+				tt.request.Cursor = first.NextCursor
+			}
+			if _, err := store.Orders.ListOrders(ctx, tt.request); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("ListOrders error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
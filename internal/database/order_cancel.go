@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+)
+
+var (
+	ErrOrderNotCancellable = errors.New("order cannot be cancelled")
+	// * ErrPaymentGateway dipakai kalau Midtrans nolak / gagal dihubungi
+	ErrPaymentGateway = errors.New("payment gateway error")
+)
+
+// * CancelOrder batalin order yang belum dibayar, termasuk transaksi di Midtrans.
+// * Aman dipanggil berkali-kali: order yang sudah cancelled langsung dikembalikan.
+func CancelOrder(ctx context.Context, store *Store, paymentGateway gateway.PaymentGateway, orderID string) (*Order, error) {
+	order, err := store.Orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == enums.OrderStatusCancelled {
+		return order, nil
+	}
+	if order.PaymentStatus != enums.PaymentStatusPending && order.PaymentStatus != enums.PaymentStatusChallenge {
+		return nil, fmt.Errorf("%w: payment is already %s", ErrOrderNotCancellable, order.PaymentStatus)
+	}
+
+	if !order.ManualPayment {
+		if err := cancelGatewayTransaction(paymentGateway, orderID); err != nil {
+			return nil, err
+		}
+	}
+
+	return markOrderCancelled(ctx, store, orderID, enums.PaymentStatusFailure)
+}
+
+// * cancelGatewayTransaction coba cancel dulu, kalau Midtrans nolak (beberapa tipe pending cuma bisa di-expire) baru expire
+func cancelGatewayTransaction(paymentGateway gateway.PaymentGateway, orderID string) error {
+	_, cancelErr := paymentGateway.CancelTransaction(orderID)
+	if cancelErr == nil || cancelErr.GetStatusCode() == http.StatusNotFound {
+		return nil
+	}
+
+	_, expireErr := paymentGateway.ExpireTransaction(orderID)
+	if expireErr == nil || expireErr.GetStatusCode() == http.StatusNotFound {
+		return nil
+	}
+
+	return fmt.Errorf("%w: failed to cancel midtrans transaction %s: cancel: %v, expire: %v", ErrPaymentGateway, orderID, cancelErr.GetMessage(), expireErr.GetMessage())
+}
+
+// * markOrderCancelled nyimpen status cancelled sekaligus lepas reservasi meja yang terhubung
+func markOrderCancelled(ctx context.Context, store *Store, orderID string, paymentStatus enums.PaymentStatus) (*Order, error) {
+	orderStatus := enums.OrderStatusCancelled
+	return store.Orders.UpdateOrder(ctx, orderID, UpdateOrderRequest{
+		OrderStatus:     &orderStatus,
+		PaymentStatus:   &paymentStatus,
+		SyncReservation: true,
+	})
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+	"github.com/Rizz404/midtrans-handler/internal/paymentstatus"
+)
+
+type SweepExpiredOrdersResult struct {
+	Checked   int
+	Cancelled int
+	Skipped   int
+	Failed    int
+}
+
+// * SweepExpiredOrders nyari order pending yang sudah lewat PaymentExpiry, cek dulu ke Midtrans,
+// * lalu cancel dan lepas reservasinya. Jaga-jaga kalau webhook expire gak pernah sampai.
+// * Kalau dryRun, cuma di-log tanpa ngubah apa-apa (termasuk di Midtrans).
+func SweepExpiredOrders(ctx context.Context, store *Store, paymentGateway gateway.PaymentGateway, now time.Time, batchSize int, dryRun bool) (SweepExpiredOrdersResult, error) {
+	result := SweepExpiredOrdersResult{}
+
+	orders, err := store.Orders.GetExpiredPendingOrders(ctx, now, batchSize)
+	if err != nil {
+		return result, err
+	}
+
+	for _, order := range orders {
+		result.Checked++
+
+		paymentStatus, ok, err := expiredOrderPaymentStatus(paymentGateway, order, dryRun)
+		if err != nil {
+			result.Failed++
+			log.Printf("SWEEPER: Failed to check order %s: %v", order.ID, err)
+			continue
+		}
+		if !ok {
+			result.Skipped++
+			continue
+		}
+
+		if dryRun {
+			result.Cancelled++
+			log.Printf("SWEEPER: [dry-run] Would cancel order %s (expired at %s) with payment status %s", order.ID, order.PaymentExpiry.Format(time.RFC3339), paymentStatus)
+			continue
+		}
+
+		var transitionErr *InvalidTransitionError
+		_, err = markOrderCancelled(ctx, store, order.ID, paymentStatus)
+		switch {
+		case errors.As(err, &transitionErr):
+			// * Webhook keburu masuk duluan
+			result.Skipped++
+		case err != nil:
+			result.Failed++
+			log.Printf("SWEEPER: Failed to cancel order %s: %v", order.ID, err)
+		default:
+			result.Cancelled++
+			log.Printf("SWEEPER: Cancelled expired order %s", order.ID)
+		}
+	}
+
+	return result, nil
+}
+
+// * expiredOrderPaymentStatus ngembaliin status pembayaran akhir order yang expired.
+// * ok false berarti order jangan di-cancel (misalnya ternyata sudah dibayar di Midtrans).
+func expiredOrderPaymentStatus(paymentGateway gateway.PaymentGateway, order Order, dryRun bool) (enums.PaymentStatus, bool, error) {
+	if order.ManualPayment {
+		return enums.PaymentStatusFailure, true, nil
+	}
+
+	status, midtransErr := paymentGateway.CheckTransaction(order.ID)
+	if midtransErr != nil {
+		if midtransErr.GetStatusCode() == http.StatusNotFound {
+			return enums.PaymentStatusFailure, true, nil
+		}
+		return "", false, fmt.Errorf("%w: %v", ErrPaymentGateway, midtransErr.GetMessage())
+	}
+
+	if status.TransactionStatus == paymentstatus.TransactionPending {
+		// * Midtrans belum nge-expire (beda jam dikit), expire manual biar customer gak bisa bayar lagi
+		if dryRun {
+			return enums.PaymentStatusFailure, true, nil
+		}
+		if _, expireErr := paymentGateway.ExpireTransaction(order.ID); expireErr != nil && expireErr.GetStatusCode() != http.StatusNotFound {
+			return "", false, fmt.Errorf("%w: failed to expire transaction: %v", ErrPaymentGateway, expireErr.GetMessage())
+		}
+		return enums.PaymentStatusFailure, true, nil
+	}
+
+	mapped := paymentstatus.FromMidtrans(status.TransactionStatus, status.FraudStatus)
+	if mapped.OrderStatus != nil && *mapped.OrderStatus == enums.OrderStatusCancelled {
+		return *mapped.PaymentStatus, true, nil
+	}
+
+	// * settlement / capture: sudah dibayar, biar webhook atau rekonsiliasi yang ngurus
+	log.Printf("SWEEPER: Order %s is %s at Midtrans, not cancelling", order.ID, status.TransactionStatus)
+	return "", false, nil
+}
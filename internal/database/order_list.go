@@ -0,0 +1,139 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+const (
+	DefaultListOrdersLimit = 20
+	MaxListOrdersLimit     = 100
+)
+
+// * ErrInvalidCursor dikembalikan ListOrders kalau cursor dari client gak bisa dibaca / dipakai buat query lain
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// * ListOrdersRequest: field kosong / nil berarti gak difilter. Hasil selalu diurutkan berdasarkan orderDate lalu ID.
+type ListOrdersRequest struct {
+	UserID          string
+	Status          enums.OrderStatus
+	PaymentStatus   enums.PaymentStatus
+	OrderType       enums.OrderType
+	PaymentMethodID string
+	OrderDateFrom   *time.Time // Inklusif
+	OrderDateTo     *time.Time // Eksklusif
+	Descending      bool
+	Limit           int
+	Cursor          string
+}
+
+type ListOrdersResult struct {
+	Orders     []Order
+	NextCursor string // Kosong kalau sudah halaman terakhir
+}
+
+// * orderCursor nyimpen posisi order terakhir di halaman sebelumnya, dikirim ke client sebagai base64 opaque.
+// * Arah urutan dan hash filter ikut disimpan, cursor cuma berlaku buat query yang sama.
+type orderCursor struct {
+	OrderDate  time.Time `json:"d"`
+	ID         string    `json:"i"`
+	Descending bool      `json:"s"`
+	Filter     string    `json:"f"`
+}
+
+func encodeOrderCursor(order Order, request ListOrdersRequest) string {
+	data, _ := json.Marshal(orderCursor{
+		OrderDate:  order.OrderDate,
+		ID:         order.ID,
+		Descending: request.Descending,
+		Filter:     request.filterHash(),
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeOrderCursor(request ListOrdersRequest) (*orderCursor, error) {
+	if request.Cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(request.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor orderCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Descending != request.Descending || cursor.Filter != request.filterHash() {
+		return nil, fmt.Errorf("%w: cursor belongs to a different sort order or filter", ErrInvalidCursor)
+	}
+	return &cursor, nil
+}
+
+// * filterHash ngeringkas semua filter (tanpa limit / cursor / arah urutan) buat dicocokkan dengan cursor
+func (request ListOrdersRequest) filterHash() string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		request.UserID,
+		string(request.Status),
+		string(request.PaymentStatus),
+		string(request.OrderType),
+		request.PaymentMethodID,
+		formatTime(request.OrderDateFrom),
+		formatTime(request.OrderDateTo),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func normalizeListOrdersLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultListOrdersLimit
+	}
+	return min(limit, MaxListOrdersLimit)
+}
+
+// * pageOrders motong hasil yang diambil limit+1 dan bikin nextCursor kalau masih ada sisa
+func pageOrders(orders []Order, request ListOrdersRequest, limit int) *ListOrdersResult {
+	result := &ListOrdersResult{Orders: orders}
+	if len(orders) > limit {
+		result.Orders = orders[:limit]
+		result.NextCursor = encodeOrderCursor(orders[limit-1], request)
+	}
+	if result.Orders == nil {
+		result.Orders = []Order{}
+	}
+	return result
+}
+
+func (request ListOrdersRequest) matches(order Order) bool {
+	switch {
+	case request.UserID != "" && order.UserID != request.UserID,
+		request.Status != "" && order.Status != request.Status,
+		request.PaymentStatus != "" && order.PaymentStatus != request.PaymentStatus,
+		request.OrderType != "" && order.OrderType != request.OrderType,
+		request.PaymentMethodID != "" && order.PaymentMethodID != request.PaymentMethodID,
+		request.OrderDateFrom != nil && order.OrderDate.Before(*request.OrderDateFrom),
+		request.OrderDateTo != nil && !order.OrderDate.Before(*request.OrderDateTo):
+		return false
+	}
+	return true
+}
+
+func validateListOrdersRequest(request ListOrdersRequest) error {
+	if request.OrderDateFrom != nil && request.OrderDateTo != nil && !request.OrderDateFrom.Before(*request.OrderDateTo) {
+		return fmt.Errorf("%w: orderDateFrom must be before orderDateTo", ErrInvalidOrder)
+	}
+	return nil
+}
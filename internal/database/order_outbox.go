@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+	"github.com/midtrans/midtrans-go/coreapi"
+)
+
+const OutboxTypeOrderCharge = "orderCharge"
+
+const (
+	OutboxStatusPending     = "pending"
+	OutboxStatusDone        = "done"
+	OutboxStatusCompensated = "compensated"
+	// * OutboxStatusFailed: sudah nyerah, harus dicek manual
+	OutboxStatusFailed = "failed"
+)
+
+// * OutboxEntry nyatet langkah pembuatan order yang belum selesai, disimpan di koleksi outbox (ID = order ID)
+type OutboxEntry struct {
+	ID        string `firestore:"id"`
+	OrderID   string `firestore:"orderId"`
+	Type      string `firestore:"type"`
+	Status    string `firestore:"status"`
+	Attempts  int    `firestore:"attempts"`
+	LastError string `firestore:"lastError"`
+	// * ChargeResponse diisi kalau charge sudah berhasil tapi finalize gagal, dipakai buat retry
+	ChargeResponse map[string]any `firestore:"chargeResponse,omitempty"`
+	CreatedAt      time.Time      `firestore:"createdAt"`
+	UpdatedAt      time.Time      `firestore:"updatedAt"`
+}
+
+// * finalizeChargedOrder ngisi field pembayaran dari response charge lalu ngubah order creating jadi pending
+func finalizeChargedOrder(ctx context.Context, store *Store, order Order, paymentMethod *PaymentMethod, rawCharge map[string]any) (*Order, error) {
+	var chargeResp coreapi.ChargeResponse
+	data, err := json.Marshal(rawCharge)
+	if err == nil {
+		err = json.Unmarshal(data, &chargeResp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode charge response for order %s: %v", order.ID, err)
+	}
+
+	applyChargeResponse(&order, paymentMethod, &chargeResp)
+	order.PaymentDetailsRaw = &rawCharge
+	order.Status = enums.OrderStatusPending
+
+	return store.Orders.FinalizeOrder(ctx, FinalizeOrderRequest{
+		Order:                order,
+		ClearCartMenuItemIDs: orderMenuItemIDs(order.OrderItems),
+		ChargeEvent: &PaymentEvent{
+			ID:                PaymentEventSourceCharge,
+			OrderID:           order.ID,
+			Source:            PaymentEventSourceCharge,
+			TransactionStatus: chargeResp.TransactionStatus,
+			Payload:           rawCharge,
+		},
+	})
+}
+
+// * compensateOrderCreation batalin transaksi di Midtrans (kalau ada) dan order-nya
+func compensateOrderCreation(ctx context.Context, store *Store, paymentGateway gateway.PaymentGateway, orderID string) error {
+	if err := cancelGatewayTransaction(paymentGateway, orderID); err != nil {
+		return err
+	}
+
+	var transitionErr *InvalidTransitionError
+	_, err := markOrderCancelled(ctx, store, orderID, enums.PaymentStatusFailure)
+	if err != nil && !errors.As(err, &transitionErr) && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	return updateOutboxStatus(ctx, store, orderID, OutboxStatusCompensated, "")
+}
+
+// * recordOutboxFailure nyimpen response charge biar worker bisa retry finalize
+func recordOutboxFailure(ctx context.Context, store *Store, orderID string, rawCharge map[string]any, cause error) {
+	entry, err := store.Outbox.GetOutboxEntryByID(ctx, orderID)
+	if err == nil {
+		entry.Attempts++
+		entry.LastError = cause.Error()
+		entry.ChargeResponse = rawCharge
+		err = store.Outbox.UpdateOutboxEntry(ctx, *entry)
+	}
+	if err != nil {
+		// * Worker tetap bakal nemu entry-nya, tapi tanpa response charge jadi cuma bisa di-compensate
+		log.Printf("CRITICAL: Order %s charged at Midtrans but failed to record outbox failure: %v (cause: %v)", orderID, err, cause)
+	}
+}
+
+func updateOutboxStatus(ctx context.Context, store *Store, id, status, lastError string) error {
+	entry, err := store.Outbox.GetOutboxEntryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	entry.Status = status
+	if lastError != "" {
+		entry.LastError = lastError
+	}
+	return store.Outbox.UpdateOutboxEntry(ctx, *entry)
+}
+
+type ProcessOutboxResult struct {
+	Checked     int
+	Finalized   int
+	Compensated int
+	Failed      int
+}
+
+// * ProcessOrderOutbox nyelesaiin entry outbox yang macet (lebih lama dari before):
+// * kalau response charge tersimpan, finalize diulang; kalau gak ada / sudah kebanyakan retry, order di-compensate.
+func ProcessOrderOutbox(ctx context.Context, store *Store, paymentGateway gateway.PaymentGateway, before time.Time, limit, maxAttempts int) (ProcessOutboxResult, error) {
+	result := ProcessOutboxResult{}
+
+	entries, err := store.Outbox.GetPendingOutboxEntries(ctx, before, limit)
+	if err != nil {
+		return result, err
+	}
+
+	for _, entry := range entries {
+		result.Checked++
+
+		order, err := store.Orders.GetOrderByID(ctx, entry.OrderID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			result.Failed++
+			log.Printf("OUTBOX: Failed to load order %s: %v", entry.OrderID, err)
+			continue
+		}
+		// * Sudah selesai lewat jalur lain (misalnya dibatalin user)
+		if order != nil && order.Status != enums.OrderStatusCreating {
+			if err := updateOutboxStatus(ctx, store, entry.ID, OutboxStatusDone, ""); err != nil {
+				log.Printf("OUTBOX: Failed to mark entry %s done: %v", entry.ID, err)
+			}
+			continue
+		}
+
+		if order != nil && entry.ChargeResponse != nil && entry.Attempts < maxAttempts {
+			paymentMethod, err := store.PaymentMethods.GetPaymentMethodByID(ctx, order.PaymentMethodID)
+			if err == nil {
+				_, err = finalizeChargedOrder(ctx, store, *order, paymentMethod, entry.ChargeResponse)
+			}
+			if err == nil {
+				result.Finalized++
+				log.Printf("OUTBOX: Finalized order %s", order.ID)
+				continue
+			}
+			result.Failed++
+			recordOutboxFailure(ctx, store, entry.ID, entry.ChargeResponse, err)
+			log.Printf("OUTBOX: Failed to finalize order %s (attempt %d): %v", entry.OrderID, entry.Attempts+1, err)
+			continue
+		}
+
+		if err := compensateOrderCreation(ctx, store, paymentGateway, entry.OrderID); err != nil {
+			entry.Attempts++
+			entry.LastError = err.Error()
+			if entry.Attempts >= maxAttempts {
+				entry.Status = OutboxStatusFailed
+				log.Printf("CRITICAL: Giving up on outbox entry %s: %v", entry.ID, err)
+			}
+			if updateErr := store.Outbox.UpdateOutboxEntry(ctx, entry); updateErr != nil {
+				log.Printf("OUTBOX: Failed to update entry %s: %v", entry.ID, updateErr)
+			}
+			result.Failed++
+			log.Printf("OUTBOX: Failed to compensate order %s: %v", entry.OrderID, err)
+			continue
+		}
+		result.Compensated++
+		log.Printf("OUTBOX: Compensated order %s", entry.OrderID)
+	}
+
+	return result, nil
+}
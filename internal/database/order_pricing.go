@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// * priceOrderItems ngitung ulang harga dan total dari koleksi menuItems.
+// * Dari client cuma menuItemId, quantity dan specialRequests yang dipercaya,
+// * price/total yang dikirim client cuma dicocokkan dan ditolak kalau beda.
+func priceOrderItems(ctx context.Context, menuItems MenuItemRepository, orderID string, items []OrderItem) ([]OrderItem, float64, error) {
+	if len(items) == 0 {
+		return nil, 0, fmt.Errorf("%w: order must contain at least one item", ErrInvalidOrder)
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.MenuItemId)
+	}
+
+	menu, err := menuItems.GetMenuItemsByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load menu items: %v", err)
+	}
+
+	pricedItems := make([]OrderItem, 0, len(items))
+	var totalAmount float64
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, 0, fmt.Errorf("%w: quantity for menu item %s must be greater than 0", ErrInvalidOrder, item.MenuItemId)
+		}
+
+		menuItem, ok := menu[item.MenuItemId]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: menu item %s not found", ErrInvalidOrder, item.MenuItemId)
+		}
+		if !menuItem.IsAvailable {
+			return nil, 0, fmt.Errorf("%w: menu item %s is not available", ErrInvalidOrder, menuItem.Name)
+		}
+		// * Midtrans cuma nerima rupiah bulat, harga pecahan itu salah data di menuItems
+		if menuItem.Price <= 0 || menuItem.Price != math.Trunc(menuItem.Price) {
+			return nil, 0, fmt.Errorf("menu item %s has invalid price %v", menuItem.ID, menuItem.Price)
+		}
+
+		lineTotal := menuItem.Price * float64(item.Quantity)
+		if item.Price != 0 && item.Price != menuItem.Price {
+			return nil, 0, fmt.Errorf("%w: price for menu item %s is %v, got %v", ErrInvalidOrder, item.MenuItemId, menuItem.Price, item.Price)
+		}
+		if item.Total != 0 && item.Total != lineTotal {
+			return nil, 0, fmt.Errorf("%w: total for menu item %s is %v, got %v", ErrInvalidOrder, item.MenuItemId, lineTotal, item.Total)
+		}
+
+		pricedItems = append(pricedItems, OrderItem{
+			ID:              item.ID,
+			OrderId:         orderID,
+			MenuItemId:      item.MenuItemId,
+			Quantity:        item.Quantity,
+			Price:           menuItem.Price,
+			Total:           lineTotal,
+			SpecialRequests: item.SpecialRequests,
+			MenuItem:        &menuItem,
+		})
+		totalAmount += lineTotal
+	}
+
+	return pricedItems, totalAmount, nil
+}
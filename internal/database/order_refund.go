@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+)
+
+var (
+	ErrOrderNotRefundable = errors.New("order cannot be refunded")
+	// * ErrInvalidRefund dipakai buat kesalahan input refund (nominal, dll)
+	ErrInvalidRefund = errors.New("invalid refund")
+)
+
+const (
+	RefundMethodMidtrans       = "midtrans"
+	RefundMethodMidtransDirect = "midtransDirect"
+	RefundMethodManual         = "manual"
+)
+
+// * RefundActorMidtrans dipakai sebagai actorId refund yang dicatat dari webhook / sync Midtrans
+const RefundActorMidtrans = "midtrans"
+
+type RefundOrderRequest struct {
+	// * Amount nil berarti refund semua sisa yang belum di-refund
+	Amount  *float64
+	Reason  string
+	ActorID string
+}
+
+// * RefundOrder refund full / sebagian order yang sudah dibayar.
+// * Order manual (cash, transfer ke admin) cuma dicatat, uangnya dikembalikan admin sendiri.
+func RefundOrder(ctx context.Context, store *Store, paymentGateway gateway.PaymentGateway, orderID string, req RefundOrderRequest) (*Order, *Refund, error) {
+	order, err := store.Orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	amount := order.TotalAmount - order.RefundedAmount
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+	if _, _, err := applyRefund(*order, amount); err != nil {
+		return nil, nil, err
+	}
+
+	refund := Refund{
+		ID:      store.Refunds.NewRefundID(orderID),
+		OrderID: orderID,
+		Amount:  amount,
+		Reason:  req.Reason,
+		ActorID: req.ActorID,
+		Method:  RefundMethodManual,
+	}
+	refund.RefundKey = orderID + "-" + refund.ID
+
+	if !order.ManualPayment {
+		paymentMethod, err := store.PaymentMethods.GetPaymentMethodByID(ctx, order.PaymentMethodID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get payment method: %v", err)
+		}
+		if err := refundGatewayTransaction(paymentGateway, *paymentMethod, &refund); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// * Kalau webhook refund keduluan nyatat, CreateRefund ngembaliin refund yang sama (cocok lewat refundKey)
+	updatedOrder, storedRefund, err := store.Refunds.CreateRefund(ctx, refund)
+	if err != nil {
+		if !order.ManualPayment {
+			log.Printf("CRITICAL: Refund %s for order %s approved by Midtrans but failed to save: %v", refund.RefundKey, orderID, err)
+		}
+		return nil, nil, err
+	}
+
+	return updatedOrder, storedRefund, nil
+}
+
+type GatewayRefunds struct {
+	// * RefundAmount total yang sudah di-refund Midtrans (refund_amount), dipakai kalau Refunds kosong
+	RefundAmount string
+	Refunds      []coreapi.RefundDetails
+	// * FallbackKey jadi refundKey kalau Midtrans gak ngirim rincian refund, biasanya ID notifikasi
+	FallbackKey string
+}
+
+// * RecordGatewayRefunds nyatat refund yang dilaporkan Midtrans (webhook / sync), termasuk refund dari dashboard.
+// * Refund yang refundKey / gatewayReference-nya sudah tercatat dilewati, jadi aman dipanggil berulang.
+func RecordGatewayRefunds(ctx context.Context, store *Store, orderID string, refunds GatewayRefunds) (*Order, error) {
+	order, err := store.Orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(refunds.Refunds) == 0 {
+		total, err := strconv.ParseFloat(refunds.RefundAmount, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid refund_amount %q", ErrInvalidRefund, refunds.RefundAmount)
+		}
+		amount := total - order.RefundedAmount
+		if amount <= 0 {
+			return order, nil
+		}
+		updatedOrder, _, err := store.Refunds.CreateRefund(ctx, Refund{
+			ID:        store.Refunds.NewRefundID(orderID),
+			OrderID:   orderID,
+			Amount:    amount,
+			ActorID:   RefundActorMidtrans,
+			Method:    RefundMethodMidtrans,
+			RefundKey: refunds.FallbackKey,
+		})
+		return updatedOrder, err
+	}
+
+	for _, detail := range refunds.Refunds {
+		amount, err := strconv.ParseFloat(detail.RefundAmount, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid refund_amount %q", ErrInvalidRefund, detail.RefundAmount)
+		}
+		refund := Refund{
+			ID:        store.Refunds.NewRefundID(orderID),
+			OrderID:   orderID,
+			Amount:    amount,
+			Reason:    detail.Reason,
+			ActorID:   RefundActorMidtrans,
+			Method:    RefundMethodMidtrans,
+			RefundKey: detail.RefundKey,
+		}
+		if detail.RefundChargebackID != 0 {
+			reference := strconv.Itoa(detail.RefundChargebackID)
+			refund.GatewayReference = &reference
+			if refund.RefundKey == "" {
+				refund.RefundKey = "midtrans-" + reference
+			}
+		}
+		if refund.RefundKey == "" {
+			return nil, fmt.Errorf("%w: refund for order %s has no refund_key or refund_chargeback_id", ErrInvalidRefund, orderID)
+		}
+
+		order, _, err = store.Refunds.CreateRefund(ctx, refund)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// * findRecordedRefund nyari refund yang sudah tercatat dengan refundKey atau gatewayReference yang sama
+func findRecordedRefund(existing []Refund, refund Refund) *Refund {
+	for _, other := range existing {
+		if refund.RefundKey != "" && other.RefundKey == refund.RefundKey {
+			return &other
+		}
+		if refund.GatewayReference != nil && other.GatewayReference != nil && *other.GatewayReference == *refund.GatewayReference {
+			return &other
+		}
+	}
+	return nil
+}
+
+// * Kartu lewat refund biasa, e-wallet dan QRIS lewat direct refund.
+// * VA, gerai, bill dan direct debit gak bisa di-refund lewat API Midtrans.
+func refundGatewayTransaction(paymentGateway gateway.PaymentGateway, paymentMethod PaymentMethod, refund *Refund) error {
+	refundReq := &coreapi.RefundReq{
+		RefundKey: refund.RefundKey,
+		Amount:    int64(refund.Amount),
+		Reason:    refund.Reason,
+	}
+
+	var resp *coreapi.RefundResponse
+	var midtransErr *midtrans.Error
+	switch paymentMethod.PaymentMethodType {
+	case enums.PaymentMethodTypeCard:
+		refund.Method = RefundMethodMidtrans
+		resp, midtransErr = paymentGateway.RefundTransaction(refund.OrderID, refundReq)
+	case enums.PaymentMethodTypeEWallet, enums.PaymentMethodTypeQrCode:
+		refund.Method = RefundMethodMidtransDirect
+		resp, midtransErr = paymentGateway.DirectRefundTransaction(refund.OrderID, refundReq)
+	default:
+		return fmt.Errorf("%w: payment method type %s does not support refunds", ErrOrderNotRefundable, paymentMethod.PaymentMethodType)
+	}
+	if midtransErr != nil {
+		return fmt.Errorf("%w: failed to refund midtrans transaction %s: %v", ErrPaymentGateway, refund.OrderID, midtransErr.GetMessage())
+	}
+
+	if resp.RefundChargebackID != 0 {
+		reference := strconv.Itoa(resp.RefundChargebackID)
+		refund.GatewayReference = &reference
+	}
+	return nil
+}
+
+// * applyRefund ngitung refundedAmount dan paymentStatus baru kalau order di-refund sebesar amount
+func applyRefund(current Order, amount float64) (float64, enums.PaymentStatus, error) {
+	if current.PaymentStatus != enums.PaymentStatusSuccess && current.PaymentStatus != enums.PaymentStatusPartialRefund {
+		return 0, "", fmt.Errorf("%w: payment is %s", ErrOrderNotRefundable, current.PaymentStatus)
+	}
+
+	remaining := current.TotalAmount - current.RefundedAmount
+	if amount <= 0 || amount != math.Trunc(amount) {
+		return 0, "", fmt.Errorf("%w: amount must be a whole number greater than 0", ErrInvalidRefund)
+	}
+	if amount > remaining {
+		return 0, "", fmt.Errorf("%w: amount %.0f exceeds refundable amount %.0f", ErrInvalidRefund, amount, remaining)
+	}
+
+	refundedAmount := current.RefundedAmount + amount
+	paymentStatus := enums.PaymentStatusPartialRefund
+	if refundedAmount == current.TotalAmount {
+		paymentStatus = enums.PaymentStatusRefunded
+	}
+	if !enums.CanTransition(current.PaymentStatus, paymentStatus) {
+		return 0, "", newInvalidTransitionError("paymentStatus", current.PaymentStatus, paymentStatus)
+	}
+
+	return refundedAmount, paymentStatus, nil
+}
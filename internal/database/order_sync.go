@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+	"github.com/Rizz404/midtrans-handler/internal/paymentstatus"
+)
+
+type SyncOrderResult struct {
+	OrderID                  string              `json:"orderId"`
+	StoredPaymentStatus      enums.PaymentStatus `json:"storedPaymentStatus"`
+	GatewayTransactionStatus string              `json:"gatewayTransactionStatus,omitempty"`
+	GatewayPaymentStatus     enums.PaymentStatus `json:"gatewayPaymentStatus,omitempty"`
+	Mismatch                 bool                `json:"mismatch"`
+	Applied                  bool                `json:"applied"`
+	Error                    string              `json:"error,omitempty"`
+}
+
+// * SyncOrderPaymentStatus nyamain status pembayaran order dengan status di Midtrans (CheckTransaction).
+// * Error cuma dikembalikan kalau order gak ketemu / Midtrans gagal dihubungi, konflik transisi masuk ke result.Error.
+func SyncOrderPaymentStatus(ctx context.Context, store *Store, paymentGateway gateway.PaymentGateway, orderID string) (*SyncOrderResult, error) {
+	order, err := store.Orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncOrderResult{
+		OrderID:             orderID,
+		StoredPaymentStatus: order.PaymentStatus,
+	}
+	// * Pembayaran manual gak ada di Midtrans, order creating masih diurus outbox worker
+	if order.ManualPayment || order.Status == enums.OrderStatusCreating {
+		return result, nil
+	}
+
+	status, midtransErr := paymentGateway.CheckTransaction(orderID)
+	if midtransErr != nil {
+		if midtransErr.GetStatusCode() == http.StatusNotFound {
+			result.Mismatch = true
+			result.Error = "transaction not found at payment gateway"
+			return result, nil
+		}
+		return nil, fmt.Errorf("%w: failed to check transaction %s: %v", ErrPaymentGateway, orderID, midtransErr.GetMessage())
+	}
+	result.GatewayTransactionStatus = status.TransactionStatus
+
+	mapped := paymentstatus.FromMidtrans(status.TransactionStatus, status.FraudStatus)
+	if mapped.Outcome != paymentstatus.OutcomeUpdate {
+		return result, nil
+	}
+	updateReq := UpdateOrderRequest{
+		PaymentStatus:   mapped.PaymentStatus,
+		OrderStatus:     mapped.OrderStatus,
+		SyncReservation: true,
+	}
+	result.GatewayPaymentStatus = *mapped.PaymentStatus
+	isRefund := paymentstatus.IsRefund(status.TransactionStatus)
+	// * Partial refund kedua statusnya tetap partialRefund, jadi refund dibandingin dari nominalnya
+	gatewayRefunded, _ := strconv.ParseFloat(status.RefundAmount, 64)
+	if result.GatewayPaymentStatus == order.PaymentStatus && (!isRefund || gatewayRefunded == order.RefundedAmount) {
+		return result, nil
+	}
+	result.Mismatch = true
+
+	if isRefund {
+		_, err = RecordGatewayRefunds(ctx, store, orderID, GatewayRefunds{
+			RefundAmount: status.RefundAmount,
+			Refunds:      status.Refunds,
+			FallbackKey:  "sync-" + status.TransactionID + "-" + status.RefundAmount,
+		})
+	} else {
+		_, err = store.Orders.UpdateOrder(ctx, orderID, updateReq)
+	}
+	var transitionErr *InvalidTransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		result.Error = transitionErr.Error()
+		return result, nil
+	case errors.Is(err, ErrOrderNotRefundable), errors.Is(err, ErrInvalidRefund):
+		result.Error = err.Error()
+		return result, nil
+	case err != nil:
+		return nil, err
+	}
+	result.Applied = true
+
+	return result, nil
+}
+
+type ReconciliationReport struct {
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt"`
+	Checked    int               `json:"checked"`
+	Applied    int               `json:"applied"`
+	Failed     int               `json:"failed"`
+	Mismatches []SyncOrderResult `json:"mismatches"`
+}
+
+// * reconcilePaymentStatuses itu status yang masih nunggu keputusan Midtrans. Order success / partialRefund
+// * gak ikut: kalau statusnya cocok, updatedAt-nya gak pernah berubah dan bakal menuhin batch terus
+// * sampai order pending gak pernah kebagian. Refund dari dashboard tetap masuk lewat webhook.
+var reconcilePaymentStatuses = []enums.PaymentStatus{
+	enums.PaymentStatusPending,
+	enums.PaymentStatusChallenge,
+}
+
+// * ReconcileOrders ngejalanin SyncOrderPaymentStatus ke order yang statusnya belum final,
+// * hasilnya laporan semua order yang statusnya beda dengan Midtrans.
+func ReconcileOrders(ctx context.Context, store *Store, paymentGateway gateway.PaymentGateway, limit int) (*ReconciliationReport, error) {
+	report := &ReconciliationReport{
+		StartedAt:  time.Now(),
+		Mismatches: []SyncOrderResult{},
+	}
+
+	orders, err := store.Orders.GetGatewayOrdersByPaymentStatus(ctx, reconcilePaymentStatuses, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		report.Checked++
+
+		result, err := SyncOrderPaymentStatus(ctx, store, paymentGateway, order.ID)
+		if err != nil {
+			report.Failed++
+			log.Printf("RECONCILE: Failed to sync order %s: %v", order.ID, err)
+			continue
+		}
+		if result.Mismatch {
+			report.Mismatches = append(report.Mismatches, *result)
+		}
+		if result.Applied {
+			report.Applied++
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+type firestoreOutboxRepository struct {
+	client *firestore.Client
+}
+
+func outboxEntryData(entry OutboxEntry) map[string]any {
+	data := map[string]any{
+		"id":        entry.ID,
+		"orderId":   entry.OrderID,
+		"type":      entry.Type,
+		"status":    entry.Status,
+		"attempts":  entry.Attempts,
+		"lastError": entry.LastError,
+		"createdAt": firestore.ServerTimestamp,
+		"updatedAt": firestore.ServerTimestamp,
+	}
+	if entry.ChargeResponse != nil {
+		data["chargeResponse"] = entry.ChargeResponse
+	}
+	return data
+}
+
+func (r *firestoreOutboxRepository) GetOutboxEntryByID(ctx context.Context, id string) (*OutboxEntry, error) {
+	docSnapshot, err := r.client.Collection("outbox").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox entry %s: %w", id, mapFirestoreError(err))
+	}
+
+	var entry OutboxEntry
+	if err := docSnapshot.DataTo(&entry); err != nil {
+		return nil, fmt.Errorf("failed to decode outbox entry %s: %v", id, err)
+	}
+
+	return &entry, nil
+}
+
+func (r *firestoreOutboxRepository) GetPendingOutboxEntries(ctx context.Context, before time.Time, limit int) ([]OutboxEntry, error) {
+	docs, err := r.client.Collection("outbox").
+		Where("status", "==", OutboxStatusPending).
+		Where("updatedAt", "<=", before).
+		OrderBy("updatedAt", firestore.Asc).
+		Limit(limit).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox entries: %v", err)
+	}
+
+	entries := make([]OutboxEntry, 0, len(docs))
+	for _, doc := range docs {
+		var entry OutboxEntry
+		if err := doc.DataTo(&entry); err != nil {
+			return nil, fmt.Errorf("failed to decode outbox entry %s: %v", doc.Ref.ID, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (r *firestoreOutboxRepository) UpdateOutboxEntry(ctx context.Context, entry OutboxEntry) error {
+	updates := []firestore.Update{
+		{Path: "status", Value: entry.Status},
+		{Path: "attempts", Value: entry.Attempts},
+		{Path: "lastError", Value: entry.LastError},
+		{Path: "updatedAt", Value: firestore.ServerTimestamp},
+	}
+	if entry.ChargeResponse != nil {
+		updates = append(updates, firestore.Update{Path: "chargeResponse", Value: entry.ChargeResponse})
+	}
+
+	if _, err := r.client.Collection("outbox").Doc(entry.ID).Update(ctx, updates); err != nil {
+		return fmt.Errorf("failed to update outbox entry %s: %w", entry.ID, mapFirestoreError(err))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type memoryOutboxRepository struct {
+	db *MemoryDB
+}
+
+func (r *memoryOutboxRepository) GetOutboxEntryByID(ctx context.Context, id string) (*OutboxEntry, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	entry, ok := r.db.outbox[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get outbox entry %s: %w", id, ErrNotFound)
+	}
+	return &entry, nil
+}
+
+func (r *memoryOutboxRepository) GetPendingOutboxEntries(ctx context.Context, before time.Time, limit int) ([]OutboxEntry, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var entries []OutboxEntry
+	for _, entry := range r.db.outbox {
+		if entry.Status == OutboxStatusPending && !entry.UpdatedAt.After(before) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].UpdatedAt.Before(entries[j].UpdatedAt)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+func (r *memoryOutboxRepository) UpdateOutboxEntry(ctx context.Context, entry OutboxEntry) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	current, ok := r.db.outbox[entry.ID]
+	if !ok {
+		return fmt.Errorf("failed to update outbox entry %s: %w", entry.ID, ErrNotFound)
+	}
+
+	entry.CreatedAt = current.CreatedAt
+	entry.UpdatedAt = time.Now()
+	r.db.outbox[entry.ID] = entry
+	return nil
+}
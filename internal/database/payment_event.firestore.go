@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+)
+
+type firestorePaymentEventRepository struct {
+	client *firestore.Client
+}
+
+func paymentEventData(event PaymentEvent) map[string]any {
+	return map[string]any{
+		"id":                event.ID,
+		"orderId":           event.OrderID,
+		"source":            event.Source,
+		"transactionStatus": event.TransactionStatus,
+		"payload":           event.Payload,
+		"createdAt":         firestore.ServerTimestamp,
+	}
+}
+
+func (r *firestorePaymentEventRepository) paymentEvents(orderID string) *firestore.CollectionRef {
+	return r.client.Collection("orders").Doc(orderID).Collection("paymentEvents")
+}
+
+func (r *firestorePaymentEventRepository) RecordPaymentEvent(ctx context.Context, event PaymentEvent) error {
+	if _, err := r.paymentEvents(event.OrderID).Doc(event.ID).Set(ctx, paymentEventData(event)); err != nil {
+		return fmt.Errorf("failed to record payment event %s for order %s: %v", event.ID, event.OrderID, err)
+	}
+	return nil
+}
+
+func (r *firestorePaymentEventRepository) GetPaymentEventsByOrderID(ctx context.Context, orderID string) ([]PaymentEvent, error) {
+	docs, err := r.paymentEvents(orderID).OrderBy("createdAt", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment events for order %s: %v", orderID, err)
+	}
+
+	events := make([]PaymentEvent, 0, len(docs))
+	for _, doc := range docs {
+		var event PaymentEvent
+		if err := doc.DataTo(&event); err != nil {
+			return nil, fmt.Errorf("failed to decode payment event %s: %v", doc.Ref.ID, err)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
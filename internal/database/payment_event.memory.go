@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"time"
+)
+
+type memoryPaymentEventRepository struct {
+	db *MemoryDB
+}
+
+// * putPaymentEvent harus dipanggil sambil pegang db.mu
+func (db *MemoryDB) putPaymentEvent(event PaymentEvent) {
+	if db.paymentEvents[event.OrderID] == nil {
+		db.paymentEvents[event.OrderID] = map[string]PaymentEvent{}
+	}
+	event.Payload = maps.Clone(event.Payload)
+	db.paymentEvents[event.OrderID][event.ID] = event
+}
+
+func (r *memoryPaymentEventRepository) RecordPaymentEvent(ctx context.Context, event PaymentEvent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	event.CreatedAt = time.Now()
+	r.db.putPaymentEvent(event)
+	return nil
+}
+
+func (r *memoryPaymentEventRepository) GetPaymentEventsByOrderID(ctx context.Context, orderID string) ([]PaymentEvent, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	events := make([]PaymentEvent, 0, len(r.db.paymentEvents[orderID]))
+	for _, event := range r.db.paymentEvents[orderID] {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+
+	return events, nil
+}
@@ -9,6 +9,10 @@ import (
 	"google.golang.org/api/iterator"
 )
 
+type firestorePaymentMethodRepository struct {
+	client *firestore.Client
+}
+
 type CreatePaymentMethodRequest struct {
 	Name                      string
 	Description               string
@@ -32,8 +36,8 @@ type UpdatePaymentMethodRequest struct {
 	AdminPaymentQrCodePicture *string
 }
 
-func CreatePaymentMethod(ctx context.Context, client *firestore.Client, request CreatePaymentMethodRequest) (*PaymentMethod, error) {
-	docRef := client.Collection("paymentMethods").NewDoc()
+func (r *firestorePaymentMethodRepository) CreatePaymentMethod(ctx context.Context, request CreatePaymentMethodRequest) (*PaymentMethod, error) {
+	docRef := r.client.Collection("paymentMethods").NewDoc()
 
 	initialData := map[string]any{
 		"id":                        docRef.ID,
@@ -68,16 +72,16 @@ func CreatePaymentMethod(ctx context.Context, client *firestore.Client, request
 	return &newPaymentMethod, nil
 }
 
-func BulkCreatePaymentMethods(ctx context.Context, client *firestore.Client, requests []CreatePaymentMethodRequest) ([]PaymentMethod, error) {
+func (r *firestorePaymentMethodRepository) BulkCreatePaymentMethods(ctx context.Context, requests []CreatePaymentMethodRequest) ([]PaymentMethod, error) {
 	if len(requests) == 0 {
 		return []PaymentMethod{}, nil
 	}
 
-	batch := client.Batch()
+	batch := r.client.Batch()
 	newDocIDs := make([]string, 0, len(requests))
 
 	for _, request := range requests {
-		docRef := client.Collection("paymentMethods").NewDoc()
+		docRef := r.client.Collection("paymentMethods").NewDoc()
 		newDocIDs = append(newDocIDs, docRef.ID)
 
 		initialData := map[string]any{
@@ -103,7 +107,7 @@ func BulkCreatePaymentMethods(ctx context.Context, client *firestore.Client, req
 	}
 
 	var createdPaymentMethods []PaymentMethod
-	iter := client.Collection("paymentMethods").Where("id", "in", newDocIDs).Documents(ctx)
+	iter := r.client.Collection("paymentMethods").Where("id", "in", newDocIDs).Documents(ctx)
 	defer iter.Stop()
 
 	for {
@@ -125,9 +129,9 @@ func BulkCreatePaymentMethods(ctx context.Context, client *firestore.Client, req
 	return createdPaymentMethods, nil
 }
 
-func GetAllPaymentMethods(ctx context.Context, client *firestore.Client) ([]PaymentMethod, error) {
+func (r *firestorePaymentMethodRepository) GetAllPaymentMethods(ctx context.Context) ([]PaymentMethod, error) {
 	var paymentMethods []PaymentMethod
-	iter := client.Collection("paymentMethods").Documents(ctx)
+	iter := r.client.Collection("paymentMethods").Documents(ctx)
 	defer iter.Stop()
 
 	for {
@@ -149,11 +153,11 @@ func GetAllPaymentMethods(ctx context.Context, client *firestore.Client) ([]Paym
 	return paymentMethods, nil
 }
 
-func GetPaymentMethodByID(ctx context.Context, client *firestore.Client, id string) (*PaymentMethod, error) {
-	docRef := client.Collection("paymentMethods").Doc(id)
+func (r *firestorePaymentMethodRepository) GetPaymentMethodByID(ctx context.Context, id string) (*PaymentMethod, error) {
+	docRef := r.client.Collection("paymentMethods").Doc(id)
 	docSnapshot, err := docRef.Get(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get payment method %s: %v", id, err)
+		return nil, fmt.Errorf("failed to get payment method %s: %w", id, mapFirestoreError(err))
 	}
 
 	var paymentMethod PaymentMethod
@@ -165,8 +169,8 @@ func GetPaymentMethodByID(ctx context.Context, client *firestore.Client, id stri
 }
 
 // Todo: Belum di fix turu
-func UpdatePaymentMethod(ctx context.Context, client *firestore.Client, id string, request UpdatePaymentMethodRequest) (*PaymentMethod, error) {
-	docRef := client.Collection("paymentMethods").Doc(id)
+func (r *firestorePaymentMethodRepository) UpdatePaymentMethod(ctx context.Context, id string, request UpdatePaymentMethodRequest) (*PaymentMethod, error) {
+	docRef := r.client.Collection("paymentMethods").Doc(id)
 
 	updates := []firestore.Update{}
 	if request.Name != nil {
@@ -180,7 +184,7 @@ func UpdatePaymentMethod(ctx context.Context, client *firestore.Client, id strin
 	}
 
 	if len(updates) == 0 {
-		return GetPaymentMethodByID(ctx, client, id)
+		return r.GetPaymentMethodByID(ctx, id)
 	}
 
 	updates = append(updates, firestore.Update{Path: "updatedAt", Value: firestore.ServerTimestamp})
@@ -189,11 +193,11 @@ func UpdatePaymentMethod(ctx context.Context, client *firestore.Client, id strin
 		return nil, fmt.Errorf("failed to update payment method %s: %v", id, err)
 	}
 
-	return GetPaymentMethodByID(ctx, client, id)
+	return r.GetPaymentMethodByID(ctx, id)
 }
 
-func DeletePaymentMethod(ctx context.Context, client *firestore.Client, id string) error {
-	_, err := client.Collection("paymentMethods").Doc(id).Delete(ctx)
+func (r *firestorePaymentMethodRepository) DeletePaymentMethod(ctx context.Context, id string) error {
+	_, err := r.client.Collection("paymentMethods").Doc(id).Delete(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to delete payment method %s: %v", id, err)
 	}
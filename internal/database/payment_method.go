@@ -0,0 +1,29 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+// * IsManual true buat cash dan method yang dibayar ke admin (kode / QR statis),
+// * pembayarannya gak lewat Midtrans dan dikonfirmasi manual sama admin
+func (pm PaymentMethod) IsManual() bool {
+	return pm.MidtransIdentifier == nil
+}
+
+// * UnavailableReason ngembaliin alasan payment method gak bisa dipakai buat amount ini,
+// * string kosong berarti bisa dipakai. MaximumAmount 0 artinya gak ada batas atas.
+func (pm PaymentMethod) UnavailableReason(amount float64) string {
+	if pm.IsManual() && pm.PaymentMethodType != enums.PaymentMethodTypeCash &&
+		pm.AdminPaymentCode == nil && pm.AdminPaymentQrCodePicture == nil {
+		return "payment method has no midtrans identifier or admin payment details"
+	}
+	if pm.MinimumAmount > 0 && amount < pm.MinimumAmount {
+		return fmt.Sprintf("minimum amount is %v", pm.MinimumAmount)
+	}
+	if pm.MaximumAmount > 0 && amount > pm.MaximumAmount {
+		return fmt.Sprintf("maximum amount is %v", pm.MaximumAmount)
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type memoryPaymentMethodRepository struct {
+	db *MemoryDB
+}
+
+func newMemoryPaymentMethod(request CreatePaymentMethodRequest, now time.Time) PaymentMethod {
+	return PaymentMethod{
+		ID:                        newMemoryID(),
+		Name:                      request.Name,
+		Description:               request.Description,
+		Logo:                      request.Logo,
+		PaymentMethodType:         request.PaymentMethodType,
+		MidtransIdentifier:        request.MidtransIdentifier,
+		MinimumAmount:             request.MinimumAmount,
+		MaximumAmount:             request.MaximumAmount,
+		AdminPaymentCode:          request.AdminPaymentCode,
+		AdminPaymentQrCodePicture: request.AdminPaymentQrCodePicture,
+		CreatedAt:                 now,
+		UpdatedAt:                 now,
+	}
+}
+
+func (r *memoryPaymentMethodRepository) CreatePaymentMethod(ctx context.Context, request CreatePaymentMethodRequest) (*PaymentMethod, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	paymentMethod := newMemoryPaymentMethod(request, time.Now())
+	r.db.paymentMethods[paymentMethod.ID] = paymentMethod
+
+	return &paymentMethod, nil
+}
+
+func (r *memoryPaymentMethodRepository) BulkCreatePaymentMethods(ctx context.Context, requests []CreatePaymentMethodRequest) ([]PaymentMethod, error) {
+	if len(requests) == 0 {
+		return []PaymentMethod{}, nil
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	createdPaymentMethods := make([]PaymentMethod, 0, len(requests))
+	for _, request := range requests {
+		createdPaymentMethods = append(createdPaymentMethods, newMemoryPaymentMethod(request, now))
+	}
+	for _, pm := range createdPaymentMethods {
+		r.db.paymentMethods[pm.ID] = pm
+	}
+
+	return createdPaymentMethods, nil
+}
+
+func (r *memoryPaymentMethodRepository) GetAllPaymentMethods(ctx context.Context) ([]PaymentMethod, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var paymentMethods []PaymentMethod
+	for _, id := range sortedKeys(r.db.paymentMethods) {
+		paymentMethods = append(paymentMethods, r.db.paymentMethods[id])
+	}
+
+	return paymentMethods, nil
+}
+
+func (r *memoryPaymentMethodRepository) GetPaymentMethodByID(ctx context.Context, id string) (*PaymentMethod, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	paymentMethod, ok := r.db.paymentMethods[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get payment method %s: %w", id, ErrNotFound)
+	}
+
+	return &paymentMethod, nil
+}
+
+func (r *memoryPaymentMethodRepository) UpdatePaymentMethod(ctx context.Context, id string, request UpdatePaymentMethodRequest) (*PaymentMethod, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	paymentMethod, ok := r.db.paymentMethods[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to update payment method %s: %w", id, ErrNotFound)
+	}
+
+	// * Field yang di-update sengaja disamain sama implementasi Firestore
+	changed := false
+	if request.Name != nil {
+		paymentMethod.Name = *request.Name
+		changed = true
+	}
+	if request.Description != nil {
+		paymentMethod.Description = *request.Description
+		changed = true
+	}
+	if request.Logo != nil {
+		paymentMethod.Logo = request.Logo
+		changed = true
+	}
+
+	if changed {
+		paymentMethod.UpdatedAt = time.Now()
+		r.db.paymentMethods[id] = paymentMethod
+	}
+
+	return &paymentMethod, nil
+}
+
+func (r *memoryPaymentMethodRepository) DeletePaymentMethod(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	delete(r.db.paymentMethods, id)
+	return nil
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+type firestorePaymentNotificationRepository struct {
+	client *firestore.Client
+}
+
+func (r *firestorePaymentNotificationRepository) CreatePaymentNotification(ctx context.Context, notification PaymentNotification) error {
+	docRef := r.client.Collection("paymentNotifications").Doc(notification.ID)
+
+	data := map[string]any{
+		"id":                notification.ID,
+		"orderId":           notification.OrderID,
+		"transactionId":     notification.TransactionID,
+		"transactionStatus": notification.TransactionStatus,
+		"fraudStatus":       notification.FraudStatus,
+		"statusCode":        notification.StatusCode,
+		"grossAmount":       notification.GrossAmount,
+		"paymentType":       notification.PaymentType,
+		"merchantId":        notification.MerchantID,
+		"result":            "",
+		"createdAt":         firestore.ServerTimestamp,
+	}
+
+	// * Create (bukan Set) biar gagal kalau notifikasi yang sama sudah pernah dicatat
+	if _, err := docRef.Create(ctx, data); err != nil {
+		return fmt.Errorf("failed to create payment notification %s: %w", notification.ID, mapFirestoreError(err))
+	}
+
+	return nil
+}
+
+func (r *firestorePaymentNotificationRepository) GetPaymentNotificationByID(ctx context.Context, id string) (*PaymentNotification, error) {
+	docSnapshot, err := r.client.Collection("paymentNotifications").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment notification %s: %w", id, mapFirestoreError(err))
+	}
+
+	var notification PaymentNotification
+	if err := docSnapshot.DataTo(&notification); err != nil {
+		return nil, fmt.Errorf("failed to decode payment notification %s: %v", id, err)
+	}
+
+	return &notification, nil
+}
+
+func (r *firestorePaymentNotificationRepository) MarkPaymentNotificationProcessed(ctx context.Context, id string, result string, reason string) error {
+	_, err := r.client.Collection("paymentNotifications").Doc(id).Update(ctx, []firestore.Update{
+		{Path: "result", Value: result},
+		{Path: "reason", Value: reason},
+		{Path: "processedAt", Value: time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark payment notification %s: %w", id, mapFirestoreError(err))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type memoryPaymentNotificationRepository struct {
+	db *MemoryDB
+}
+
+func (r *memoryPaymentNotificationRepository) CreatePaymentNotification(ctx context.Context, notification PaymentNotification) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, exists := r.db.notifications[notification.ID]; exists {
+		return fmt.Errorf("failed to create payment notification %s: %w", notification.ID, ErrAlreadyExists)
+	}
+
+	notification.Result = ""
+	notification.Reason = ""
+	notification.ProcessedAt = nil
+	notification.CreatedAt = time.Now()
+	r.db.notifications[notification.ID] = notification
+
+	return nil
+}
+
+func (r *memoryPaymentNotificationRepository) GetPaymentNotificationByID(ctx context.Context, id string) (*PaymentNotification, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	notification, ok := r.db.notifications[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get payment notification %s: %w", id, ErrNotFound)
+	}
+
+	return &notification, nil
+}
+
+func (r *memoryPaymentNotificationRepository) MarkPaymentNotificationProcessed(ctx context.Context, id string, result string, reason string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	notification, ok := r.db.notifications[id]
+	if !ok {
+		return fmt.Errorf("failed to mark payment notification %s: %w", id, ErrNotFound)
+	}
+
+	now := time.Now()
+	notification.Result = result
+	notification.Reason = reason
+	notification.ProcessedAt = &now
+	r.db.notifications[id] = notification
+
+	return nil
+}
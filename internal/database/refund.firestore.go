@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+)
+
+type firestoreRefundRepository struct {
+	client *firestore.Client
+}
+
+func (r *firestoreRefundRepository) refunds(orderID string) *firestore.CollectionRef {
+	return r.client.Collection("orders").Doc(orderID).Collection("refunds")
+}
+
+func (r *firestoreRefundRepository) NewRefundID(orderID string) string {
+	return r.refunds(orderID).NewDoc().ID
+}
+
+func (r *firestoreRefundRepository) CreateRefund(ctx context.Context, refund Refund) (*Order, *Refund, error) {
+	orderRef := r.client.Collection("orders").Doc(refund.OrderID)
+	refundRef := r.refunds(refund.OrderID).Doc(refund.ID)
+
+	var recorded *Refund
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// * Firestore transaction: semua read harus sebelum write
+		recorded = nil
+		docSnapshot, err := tx.Get(orderRef)
+		if err != nil {
+			return mapFirestoreError(err)
+		}
+		var current Order
+		if err := docSnapshot.DataTo(&current); err != nil {
+			return fmt.Errorf("failed to decode order %s: %v", refund.OrderID, err)
+		}
+
+		refundDocs, err := tx.Documents(r.refunds(refund.OrderID)).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to get refunds for order %s: %v", refund.OrderID, err)
+		}
+		existing := make([]Refund, 0, len(refundDocs))
+		for _, doc := range refundDocs {
+			var other Refund
+			if err := doc.DataTo(&other); err != nil {
+				return fmt.Errorf("failed to decode refund %s: %v", doc.Ref.ID, err)
+			}
+			existing = append(existing, other)
+		}
+		if recorded = findRecordedRefund(existing, refund); recorded != nil {
+			return nil
+		}
+
+		refundedAmount, paymentStatus, err := applyRefund(current, refund.Amount)
+		if err != nil {
+			return err
+		}
+
+		data := map[string]any{
+			"id":        refund.ID,
+			"orderId":   refund.OrderID,
+			"amount":    refund.Amount,
+			"reason":    refund.Reason,
+			"actorId":   refund.ActorID,
+			"method":    refund.Method,
+			"refundKey": refund.RefundKey,
+			"createdAt": firestore.ServerTimestamp,
+		}
+		if refund.GatewayReference != nil {
+			data["gatewayReference"] = *refund.GatewayReference
+		}
+		if err := tx.Create(refundRef, data); err != nil {
+			return err
+		}
+
+		return tx.Update(orderRef, []firestore.Update{
+			{Path: "refundedAmount", Value: refundedAmount},
+			{Path: "paymentStatus", Value: paymentStatus},
+			{Path: "updatedAt", Value: firestore.ServerTimestamp},
+		})
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create refund for order %s: %w", refund.OrderID, err)
+	}
+	if recorded != nil {
+		refund = *recorded
+	}
+
+	orders := &firestoreOrderRepository{client: r.client}
+	order, err := orders.GetOrderByID(ctx, refund.OrderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return order, &refund, nil
+}
+
+func (r *firestoreRefundRepository) GetRefundsByOrderID(ctx context.Context, orderID string) ([]Refund, error) {
+	docs, err := r.refunds(orderID).OrderBy("createdAt", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunds for order %s: %v", orderID, err)
+	}
+
+	refunds := make([]Refund, 0, len(docs))
+	for _, doc := range docs {
+		var refund Refund
+		if err := doc.DataTo(&refund); err != nil {
+			return nil, fmt.Errorf("failed to decode refund %s: %v", doc.Ref.ID, err)
+		}
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, nil
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type memoryRefundRepository struct {
+	db *MemoryDB
+}
+
+func (r *memoryRefundRepository) NewRefundID(orderID string) string {
+	return newMemoryID()
+}
+
+func (r *memoryRefundRepository) CreateRefund(ctx context.Context, refund Refund) (*Order, *Refund, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	order, ok := r.db.orders[refund.OrderID]
+	if !ok {
+		return nil, nil, fmt.Errorf("failed to create refund for order %s: %w", refund.OrderID, ErrNotFound)
+	}
+	if _, exists := r.db.refunds[refund.OrderID][refund.ID]; exists {
+		return nil, nil, fmt.Errorf("failed to create refund for order %s: %w", refund.OrderID, ErrAlreadyExists)
+	}
+
+	existing := make([]Refund, 0, len(r.db.refunds[refund.OrderID]))
+	for _, other := range r.db.refunds[refund.OrderID] {
+		existing = append(existing, other)
+	}
+	if recorded := findRecordedRefund(existing, refund); recorded != nil {
+		order = copyOrder(order)
+		return &order, recorded, nil
+	}
+
+	refundedAmount, paymentStatus, err := applyRefund(copyOrder(order), refund.Amount)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create refund for order %s: %w", refund.OrderID, err)
+	}
+
+	now := time.Now()
+	refund.CreatedAt = now
+	if r.db.refunds[refund.OrderID] == nil {
+		r.db.refunds[refund.OrderID] = map[string]Refund{}
+	}
+	r.db.refunds[refund.OrderID][refund.ID] = refund
+
+	order.RefundedAmount = refundedAmount
+	order.PaymentStatus = paymentStatus
+	order.UpdatedAt = now
+	r.db.orders[order.ID] = order
+
+	order = copyOrder(order)
+	return &order, &refund, nil
+}
+
+func (r *memoryRefundRepository) GetRefundsByOrderID(ctx context.Context, orderID string) ([]Refund, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	refunds := make([]Refund, 0, len(r.db.refunds[orderID]))
+	for _, refund := range r.db.refunds[orderID] {
+		refunds = append(refunds, refund)
+	}
+	sort.Slice(refunds, func(i, j int) bool {
+		return refunds[i].CreatedAt.Before(refunds[j].CreatedAt)
+	})
+
+	return refunds, nil
+}
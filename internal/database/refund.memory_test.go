@@ -0,0 +1,155 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+func newTestRefundStore(t *testing.T) *Store {
+	t.Helper()
+	store := NewMemoryStore()
+	err := store.Orders.CreateOrder(context.Background(), CreateOrderRequest{Order: Order{
+		ID:            "o1",
+		UserID:        "u1",
+		Status:        enums.OrderStatusConfirmed,
+		PaymentStatus: enums.PaymentStatusSuccess,
+		TotalAmount:   10000,
+	}})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	return store
+}
+
+func TestMemoryCreateRefund(t *testing.T) {
+	store := newTestRefundStore(t)
+	ctx := context.Background()
+
+	order, refund, err := store.Refunds.CreateRefund(ctx, Refund{ID: "r1", OrderID: "o1", Amount: 4000, RefundKey: "k1"})
+	if err != nil {
+		t.Fatalf("CreateRefund: %v", err)
+	}
+	if order.RefundedAmount != 4000 || order.PaymentStatus != enums.PaymentStatusPartialRefund {
+		t.Errorf("order = %v / %s, want 4000 / partialRefund", order.RefundedAmount, order.PaymentStatus)
+	}
+	if refund.ID != "r1" {
+		t.Errorf("refund ID = %s, want r1", refund.ID)
+	}
+
+	order, _, err = store.Refunds.CreateRefund(ctx, Refund{ID: "r2", OrderID: "o1", Amount: 6000, RefundKey: "k2"})
+	if err != nil {
+		t.Fatalf("CreateRefund: %v", err)
+	}
+	if order.RefundedAmount != 10000 || order.PaymentStatus != enums.PaymentStatusRefunded {
+		t.Errorf("order = %v / %s, want 10000 / refunded", order.RefundedAmount, order.PaymentStatus)
+	}
+}
+
+func TestMemoryCreateRefundIsIdempotent(t *testing.T) {
+	reference := "123"
+
+	tests := []struct {
+		name  string
+		retry Refund
+	}{
+		{
+			name:  "same refund key",
+			retry: Refund{ID: "r2", OrderID: "o1", Amount: 10000, RefundKey: "k1"},
+		},
+		{
+			name:  "same gateway reference",
+			retry: Refund{ID: "r2", OrderID: "o1", Amount: 10000, RefundKey: "midtrans-123", GatewayReference: &reference},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestRefundStore(t)
+			ctx := context.Background()
+
+			// * Refund penuh bikin order refunded, jadi retry cuma lolos kalau dikenali sebagai refund yang sama
+			_, _, err := store.Refunds.CreateRefund(ctx, Refund{ID: "r1", OrderID: "o1", Amount: 10000, RefundKey: "k1", GatewayReference: &reference})
+			if err != nil {
+				t.Fatalf("CreateRefund: %v", err)
+			}
+
+			order, refund, err := store.Refunds.CreateRefund(ctx, tt.retry)
+			if err != nil {
+				t.Fatalf("CreateRefund retry: %v", err)
+			}
+			if refund.ID != "r1" {
+				t.Errorf("refund ID = %s, want the recorded r1", refund.ID)
+			}
+			if order.RefundedAmount != 10000 {
+				t.Errorf("RefundedAmount = %v, retry must not be counted twice", order.RefundedAmount)
+			}
+			if refunds, _ := store.Refunds.GetRefundsByOrderID(ctx, "o1"); len(refunds) != 1 {
+				t.Errorf("got %d refunds, want 1", len(refunds))
+			}
+		})
+	}
+}
+
+func TestMemoryCreateRefundRollsBack(t *testing.T) {
+	tests := []struct {
+		name   string
+		refund Refund
+		want   error
+	}{
+		{
+			name:   "amount exceeds remaining",
+			refund: Refund{ID: "r1", OrderID: "o1", Amount: 20000, RefundKey: "k1"},
+			want:   ErrInvalidRefund,
+		},
+		{
+			name:   "fractional amount",
+			refund: Refund{ID: "r1", OrderID: "o1", Amount: 100.5, RefundKey: "k1"},
+			want:   ErrInvalidRefund,
+		},
+		{
+			name:   "unknown order",
+			refund: Refund{ID: "r1", OrderID: "missing", Amount: 1000, RefundKey: "k1"},
+			want:   ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestRefundStore(t)
+			ctx := context.Background()
+
+			if _, _, err := store.Refunds.CreateRefund(ctx, tt.refund); !errors.Is(err, tt.want) {
+				t.Fatalf("CreateRefund error = %v, want %v", err, tt.want)
+			}
+
+			order, _ := store.Orders.GetOrderByID(ctx, "o1")
+			if order.RefundedAmount != 0 || order.PaymentStatus != enums.PaymentStatusSuccess {
+				t.Errorf("order = %v / %s, failed refund must not change the order", order.RefundedAmount, order.PaymentStatus)
+			}
+			if refunds, _ := store.Refunds.GetRefundsByOrderID(ctx, "o1"); len(refunds) != 0 {
+				t.Errorf("got %d refunds, failed refund must not be recorded", len(refunds))
+			}
+		})
+	}
+}
+
+func TestMemoryCreateRefundRejectsUnpaidOrder(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	err := store.Orders.CreateOrder(ctx, CreateOrderRequest{Order: Order{
+		ID:            "o1",
+		Status:        enums.OrderStatusPending,
+		PaymentStatus: enums.PaymentStatusPending,
+		TotalAmount:   10000,
+	}})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	if _, _, err := store.Refunds.CreateRefund(ctx, Refund{ID: "r1", OrderID: "o1", Amount: 1000, RefundKey: "k1"}); !errors.Is(err, ErrOrderNotRefundable) {
+		t.Fatalf("CreateRefund error = %v, want ErrOrderNotRefundable", err)
+	}
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+// * Sentinel error yang dikembalikan semua implementasi repository
+var (
+	ErrNotFound      = errors.New("document not found")
+	ErrAlreadyExists = errors.New("document already exists")
+	// * ErrInvalidOrder dipakai buat semua kesalahan input order (bukan error server)
+	ErrInvalidOrder = errors.New("invalid order")
+)
+
+type OrderRepository interface {
+	NewOrderID() string
+	// * CreateOrder menulis order, reservasi (opsional) dan menghapus cart item dalam satu batch atomik
+	CreateOrder(ctx context.Context, request CreateOrderRequest) error
+	GetOrderByID(ctx context.Context, id string) (*Order, error)
+	// * UpdateOrder dengan SyncReservation ikut nutup reservasi meja (dan IsOccupied-nya) di transaksi yang sama
+	UpdateOrder(ctx context.Context, id string, request UpdateOrderRequest) (*Order, error)
+	// * FinalizeOrder ngubah order creating jadi pending, nyimpen charge event, hapus cart dan nandain outbox done dalam satu transaksi
+	FinalizeOrder(ctx context.Context, request FinalizeOrderRequest) (*Order, error)
+	// * GetExpiredPendingOrders ngembaliin order pending yang paymentExpiry-nya <= before, paling lama duluan
+	GetExpiredPendingOrders(ctx context.Context, before time.Time, limit int) ([]Order, error)
+	// * GetGatewayOrdersByPaymentStatus cuma ngembaliin order Midtrans (bukan manual), yang paling lama gak di-update duluan
+	GetGatewayOrdersByPaymentStatus(ctx context.Context, statuses []enums.PaymentStatus, limit int) ([]Order, error)
+	// * ListOrders ngembaliin satu halaman order sesuai filter, lanjutkan pakai NextCursor
+	ListOrders(ctx context.Context, request ListOrdersRequest) (*ListOrdersResult, error)
+}
+
+type PaymentMethodRepository interface {
+	CreatePaymentMethod(ctx context.Context, request CreatePaymentMethodRequest) (*PaymentMethod, error)
+	BulkCreatePaymentMethods(ctx context.Context, requests []CreatePaymentMethodRequest) ([]PaymentMethod, error)
+	GetAllPaymentMethods(ctx context.Context) ([]PaymentMethod, error)
+	GetPaymentMethodByID(ctx context.Context, id string) (*PaymentMethod, error)
+	UpdatePaymentMethod(ctx context.Context, id string, request UpdatePaymentMethodRequest) (*PaymentMethod, error)
+	DeletePaymentMethod(ctx context.Context, id string) error
+}
+
+type UserRepository interface {
+	GetAllUsers(ctx context.Context) ([]User, error)
+	GetUserByID(ctx context.Context, id string) (*User, error)
+}
+
+type RestaurantTableRepository interface {
+	CreateRestaurantTable(ctx context.Context, request CreateRestaurantTableRequest) (*RestaurantTable, error)
+	GetAllRestaurantTables(ctx context.Context) ([]RestaurantTable, error)
+	GetRestaurantTableByID(ctx context.Context, id string) (*RestaurantTable, error)
+	UpdateRestaurantTable(ctx context.Context, id string, request UpdateRestaurantTableRequest) (*RestaurantTable, error)
+	// * DeleteRestaurantTable gagal dengan ErrTableInUse kalau meja masih punya reservasi reserved / occupied
+	DeleteRestaurantTable(ctx context.Context, id string) error
+}
+
+type TableReservationRepository interface {
+	NewTableReservationID() string
+	GetTableReservationByID(ctx context.Context, id string) (*TableReservation, error)
+	GetTableReservationByOrderID(ctx context.Context, orderID string) (*TableReservation, error)
+	// * ListTableReservations ngembaliin reservasi sesuai filter, urut berdasarkan reservationTime
+	ListTableReservations(ctx context.Context, request ListTableReservationsRequest) ([]TableReservation, error)
+	// * UpdateTableReservationStatus gagal dengan InvalidTransitionError kalau transisinya gak valid.
+	// * IsOccupied meja ikut diubah: true waktu occupied, false lagi setelah occupied selesai / batal.
+	UpdateTableReservationStatus(ctx context.Context, id string, status enums.ReservationStatus) (*TableReservation, error)
+}
+
+type PaymentNotificationRepository interface {
+	// * CreatePaymentNotification gagal dengan ErrAlreadyExists kalau ID-nya sudah pernah dicatat
+	CreatePaymentNotification(ctx context.Context, notification PaymentNotification) error
+	GetPaymentNotificationByID(ctx context.Context, id string) (*PaymentNotification, error)
+	// * MarkPaymentNotificationProcessed nyimpen hasil proses, reason diisi kalau notifikasi ditolak
+	MarkPaymentNotificationProcessed(ctx context.Context, id string, result string, reason string) error
+}
+
+type RefundRepository interface {
+	NewRefundID(orderID string) string
+	// * CreateRefund nyimpen refund sekaligus update refundedAmount dan paymentStatus order dalam satu transaksi.
+	// * Kalau refundKey / gatewayReference-nya sudah tercatat (misalnya keduluan webhook), refund yang lama
+	// * dikembalikan tanpa ngitung ulang.
+	CreateRefund(ctx context.Context, refund Refund) (*Order, *Refund, error)
+	GetRefundsByOrderID(ctx context.Context, orderID string) ([]Refund, error)
+}
+
+type PaymentEventRepository interface {
+	// * RecordPaymentEvent idempotent, event dengan ID yang sama cuma ditimpa
+	RecordPaymentEvent(ctx context.Context, event PaymentEvent) error
+	GetPaymentEventsByOrderID(ctx context.Context, orderID string) ([]PaymentEvent, error)
+}
+
+type OutboxRepository interface {
+	GetOutboxEntryByID(ctx context.Context, id string) (*OutboxEntry, error)
+	// * GetPendingOutboxEntries ngembaliin entry pending yang terakhir di-update <= before
+	GetPendingOutboxEntries(ctx context.Context, before time.Time, limit int) ([]OutboxEntry, error)
+	UpdateOutboxEntry(ctx context.Context, entry OutboxEntry) error
+}
+
+type IdempotencyKeyRepository interface {
+	// * ReserveIdempotencyKey nyimpen key baru dengan status processing. Kalau key masih berlaku,
+	// * record yang lama dikembalikan bareng ErrAlreadyExists. Key yang sudah expired ditimpa.
+	ReserveIdempotencyKey(ctx context.Context, key IdempotencyKey) (*IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, id string, responseStatus int, responseBody string) error
+	DeleteIdempotencyKey(ctx context.Context, id string) error
+}
+
+type CategoryRepository interface {
+	CreateCategory(ctx context.Context, request CreateCategoryRequest) (*Category, error)
+	// * GetAllCategories urut berdasarkan name
+	GetAllCategories(ctx context.Context) ([]Category, error)
+	GetCategoryByID(ctx context.Context, id string) (*Category, error)
+	// * UpdateCategory ikut memperbarui salinan kategori di semua menu item-nya
+	UpdateCategory(ctx context.Context, id string, request UpdateCategoryRequest) (*Category, error)
+	// * DeleteCategory gagal dengan ErrCategoryInUse kalau masih ada menu item di kategori itu
+	DeleteCategory(ctx context.Context, id string) error
+}
+
+type MenuItemRepository interface {
+	// * CreateMenuItem dan UpdateMenuItem gagal dengan ErrCategoryNotFound kalau categoryId-nya gak ada
+	CreateMenuItem(ctx context.Context, request CreateMenuItemRequest) (*DenormalizedMenuItem, error)
+	// * ListMenuItems ngembaliin menu item sesuai filter, urut berdasarkan name
+	ListMenuItems(ctx context.Context, request ListMenuItemsRequest) ([]DenormalizedMenuItem, error)
+	GetMenuItemByID(ctx context.Context, id string) (*DenormalizedMenuItem, error)
+	// * GetMenuItemsByIDs ngembaliin map id -> menu item, id yang gak ada cuma gak muncul di map
+	GetMenuItemsByIDs(ctx context.Context, ids []string) (map[string]DenormalizedMenuItem, error)
+	UpdateMenuItem(ctx context.Context, id string, request UpdateMenuItemRequest) (*DenormalizedMenuItem, error)
+	DeleteMenuItem(ctx context.Context, id string) error
+}
+
+// * Store ngumpulin semua repository biar gampang di-inject ke apiConfig
+type Store struct {
+	Orders            OrderRepository
+	PaymentMethods    PaymentMethodRepository
+	Users             UserRepository
+	Tables            RestaurantTableRepository
+	TableReservations TableReservationRepository
+	Notifications     PaymentNotificationRepository
+	Categories        CategoryRepository
+	MenuItems         MenuItemRepository
+	Refunds           RefundRepository
+	PaymentEvents     PaymentEventRepository
+	Outbox            OutboxRepository
+	IdempotencyKeys   IdempotencyKeyRepository
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+// * ErrTableInUse: meja masih punya reservasi reserved / occupied, jadi gak boleh dihapus
+var ErrTableInUse = errors.New("table still has active reservations")
+
+type CreateRestaurantTableRequest struct {
+	TableNumber string
+	Capacity    int
+	IsAvailable bool
+	Location    enums.Location
+}
+
+type UpdateRestaurantTableRequest struct {
+	TableNumber *string
+	Capacity    *int
+	IsAvailable *bool
+	Location    *enums.Location
+}
+
+type firestoreRestaurantTableRepository struct {
+	client *firestore.Client
+}
+
+func (r *firestoreRestaurantTableRepository) CreateRestaurantTable(ctx context.Context, request CreateRestaurantTableRequest) (*RestaurantTable, error) {
+	docRef := r.client.Collection("tables").NewDoc()
+
+	_, err := docRef.Set(ctx, map[string]any{
+		"id":          docRef.ID,
+		"tableNumber": request.TableNumber,
+		"capacity":    request.Capacity,
+		"isAvailable": request.IsAvailable,
+		"isOccupied":  false,
+		"location":    request.Location,
+		"createdAt":   firestore.ServerTimestamp,
+		"updatedAt":   firestore.ServerTimestamp,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create table: %v", err)
+	}
+
+	return r.GetRestaurantTableByID(ctx, docRef.ID)
+}
+
+func (r *firestoreRestaurantTableRepository) GetAllRestaurantTables(ctx context.Context) ([]RestaurantTable, error) {
+	docs, err := r.client.Collection("tables").OrderBy("tableNumber", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tables: %v", err)
+	}
+
+	tables := make([]RestaurantTable, 0, len(docs))
+	for _, doc := range docs {
+		var table RestaurantTable
+		if err := doc.DataTo(&table); err != nil {
+			return nil, fmt.Errorf("failed to decode table %s: %v", doc.Ref.ID, err)
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+func (r *firestoreRestaurantTableRepository) GetRestaurantTableByID(ctx context.Context, id string) (*RestaurantTable, error) {
+	docSnapshot, err := r.client.Collection("tables").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table %s: %w", id, mapFirestoreError(err))
+	}
+
+	var table RestaurantTable
+	if err := docSnapshot.DataTo(&table); err != nil {
+		return nil, fmt.Errorf("failed to decode table %s: %v", id, err)
+	}
+
+	return &table, nil
+}
+
+func (r *firestoreRestaurantTableRepository) UpdateRestaurantTable(ctx context.Context, id string, request UpdateRestaurantTableRequest) (*RestaurantTable, error) {
+	updates := []firestore.Update{}
+	if request.TableNumber != nil {
+		updates = append(updates, firestore.Update{Path: "tableNumber", Value: *request.TableNumber})
+	}
+	if request.Capacity != nil {
+		updates = append(updates, firestore.Update{Path: "capacity", Value: *request.Capacity})
+	}
+	if request.IsAvailable != nil {
+		updates = append(updates, firestore.Update{Path: "isAvailable", Value: *request.IsAvailable})
+	}
+	if request.Location != nil {
+		updates = append(updates, firestore.Update{Path: "location", Value: *request.Location})
+	}
+	if len(updates) == 0 {
+		return r.GetRestaurantTableByID(ctx, id)
+	}
+	updates = append(updates, firestore.Update{Path: "updatedAt", Value: firestore.ServerTimestamp})
+
+	if _, err := r.client.Collection("tables").Doc(id).Update(ctx, updates); err != nil {
+		return nil, fmt.Errorf("failed to update table %s: %w", id, mapFirestoreError(err))
+	}
+
+	return r.GetRestaurantTableByID(ctx, id)
+}
+
+func (r *firestoreRestaurantTableRepository) DeleteRestaurantTable(ctx context.Context, id string) error {
+	docRef := r.client.Collection("tables").Doc(id)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(docRef); err != nil {
+			return mapFirestoreError(err)
+		}
+		reservationsQuery := r.client.Collection("tableReservations").
+			Where("tableId", "==", id).
+			Where("status", "in", activeReservationStatuses).
+			Limit(1)
+		reservationDocs, err := tx.Documents(reservationsQuery).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to query reservations of table %s: %v", id, err)
+		}
+		if len(reservationDocs) > 0 {
+			return ErrTableInUse
+		}
+		return tx.Delete(docRef)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete table %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+type memoryRestaurantTableRepository struct {
+	db *MemoryDB
+}
+
+func (r *memoryRestaurantTableRepository) CreateRestaurantTable(ctx context.Context, request CreateRestaurantTableRequest) (*RestaurantTable, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	table := RestaurantTable{
+		ID:          newMemoryID(),
+		TableNumber: request.TableNumber,
+		Capacity:    request.Capacity,
+		IsAvailable: request.IsAvailable,
+		Location:    request.Location,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	r.db.tables[table.ID] = table
+
+	return &table, nil
+}
+
+func (r *memoryRestaurantTableRepository) GetAllRestaurantTables(ctx context.Context) ([]RestaurantTable, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	tables := make([]RestaurantTable, 0, len(r.db.tables))
+	for _, table := range r.db.tables {
+		tables = append(tables, table)
+	}
+	// * Sama kayak Firestore: urut berdasarkan tableNumber
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].TableNumber < tables[j].TableNumber
+	})
+
+	return tables, nil
+}
+
+func (r *memoryRestaurantTableRepository) GetRestaurantTableByID(ctx context.Context, id string) (*RestaurantTable, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	table, ok := r.db.tables[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get table %s: %w", id, ErrNotFound)
+	}
+
+	return &table, nil
+}
+
+func (r *memoryRestaurantTableRepository) UpdateRestaurantTable(ctx context.Context, id string, request UpdateRestaurantTableRequest) (*RestaurantTable, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	table, ok := r.db.tables[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to update table %s: %w", id, ErrNotFound)
+	}
+
+	if request.TableNumber != nil {
+		table.TableNumber = *request.TableNumber
+	}
+	if request.Capacity != nil {
+		table.Capacity = *request.Capacity
+	}
+	if request.IsAvailable != nil {
+		table.IsAvailable = *request.IsAvailable
+	}
+	if request.Location != nil {
+		table.Location = *request.Location
+	}
+	table.UpdatedAt = time.Now()
+	r.db.tables[id] = table
+
+	return &table, nil
+}
+
+func (r *memoryRestaurantTableRepository) DeleteRestaurantTable(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.tables[id]; !ok {
+		return fmt.Errorf("failed to delete table %s: %w", id, ErrNotFound)
+	}
+	for _, reservation := range r.db.tableReservations {
+		if reservation.TableID == id && slices.Contains(activeReservationStatuses, reservation.Status) {
+			return fmt.Errorf("failed to delete table %s: %w", id, ErrTableInUse)
+		}
+	}
+
+	delete(r.db.tables, id)
+	return nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+func TestMemoryDeleteRestaurantTable(t *testing.T) {
+	tests := []struct {
+		name              string
+		reservationStatus enums.ReservationStatus // "" berarti meja gak punya reservasi
+		want              error
+	}{
+		{name: "no reservations"},
+		{name: "completed reservation", reservationStatus: enums.StatusCompleted},
+		{name: "cancelled reservation", reservationStatus: enums.StatusCancelled},
+		{name: "reserved reservation", reservationStatus: enums.StatusReserved, want: ErrTableInUse},
+		{name: "occupied reservation", reservationStatus: enums.StatusOccupied, want: ErrTableInUse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := NewMemoryDB()
+			store := db.Store()
+			ctx := context.Background()
+
+			table, err := store.Tables.CreateRestaurantTable(ctx, CreateRestaurantTableRequest{TableNumber: "T1", Capacity: 4, IsAvailable: true})
+			if err != nil {
+				t.Fatalf("CreateRestaurantTable: %v", err)
+			}
+			if tt.reservationStatus != "" {
+				db.tableReservations["r1"] = TableReservation{
+					ID:              "r1",
+					TableID:         table.ID,
+					ReservationTime: time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC),
+					Status:          tt.reservationStatus,
+				}
+			}
+
+			err = store.Tables.DeleteRestaurantTable(ctx, table.ID)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("DeleteRestaurantTable error = %v, want %v", err, tt.want)
+			}
+
+			_, err = store.Tables.GetRestaurantTableByID(ctx, table.ID)
+			if deleted := errors.Is(err, ErrNotFound); deleted != (tt.want == nil) {
+				t.Errorf("table deleted = %v, want %v", deleted, tt.want == nil)
+			}
+		})
+	}
+}
+
+func TestMemoryDeleteRestaurantTableNotFound(t *testing.T) {
+	store := NewMemoryStore()
+
+	if err := store.Tables.DeleteRestaurantTable(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("DeleteRestaurantTable error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func NewFirestoreStore(client *firestore.Client) *Store {
+	return &Store{
+		Orders:            &firestoreOrderRepository{client: client},
+		PaymentMethods:    &firestorePaymentMethodRepository{client: client},
+		Users:             &firestoreUserRepository{client: client},
+		Tables:            &firestoreRestaurantTableRepository{client: client},
+		TableReservations: &firestoreTableReservationRepository{client: client},
+		Notifications:     &firestorePaymentNotificationRepository{client: client},
+		Categories:        &firestoreCategoryRepository{client: client},
+		MenuItems:         &firestoreMenuItemRepository{client: client},
+		Refunds:           &firestoreRefundRepository{client: client},
+		PaymentEvents:     &firestorePaymentEventRepository{client: client},
+		Outbox:            &firestoreOutboxRepository{client: client},
+		IdempotencyKeys:   &firestoreIdempotencyKeyRepository{client: client},
+	}
+}
+
+// * mapFirestoreError nerjemahin error grpc dari Firestore jadi sentinel error package ini
+func mapFirestoreError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return ErrNotFound
+	case codes.AlreadyExists:
+		return ErrAlreadyExists
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"crypto/rand"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// * MemoryDB adalah pengganti Firestore buat test dan local development.
+// * Semua koleksi dijaga satu mutex, jadi write multi-koleksi (batch) tetap atomik.
+type MemoryDB struct {
+	mu                sync.RWMutex
+	orders            map[string]Order
+	paymentMethods    map[string]PaymentMethod
+	users             map[string]User
+	tables            map[string]RestaurantTable
+	tableReservations map[string]TableReservation
+	cartItems         map[string]CartItem
+	notifications     map[string]PaymentNotification
+	categories        map[string]Category
+	menuItems         map[string]DenormalizedMenuItem
+	refunds           map[string]map[string]Refund       // orderId -> refundId -> refund
+	paymentEvents     map[string]map[string]PaymentEvent // orderId -> eventId -> event
+	outbox            map[string]OutboxEntry
+	idempotencyKeys   map[string]IdempotencyKey
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		orders:            map[string]Order{},
+		paymentMethods:    map[string]PaymentMethod{},
+		users:             map[string]User{},
+		tables:            map[string]RestaurantTable{},
+		tableReservations: map[string]TableReservation{},
+		cartItems:         map[string]CartItem{},
+		notifications:     map[string]PaymentNotification{},
+		categories:        map[string]Category{},
+		menuItems:         map[string]DenormalizedMenuItem{},
+		refunds:           map[string]map[string]Refund{},
+		paymentEvents:     map[string]map[string]PaymentEvent{},
+		outbox:            map[string]OutboxEntry{},
+		idempotencyKeys:   map[string]IdempotencyKey{},
+	}
+}
+
+func NewMemoryStore() *Store {
+	return NewMemoryDB().Store()
+}
+
+func (db *MemoryDB) Store() *Store {
+	return &Store{
+		Orders:            &memoryOrderRepository{db: db},
+		PaymentMethods:    &memoryPaymentMethodRepository{db: db},
+		Users:             &memoryUserRepository{db: db},
+		Tables:            &memoryRestaurantTableRepository{db: db},
+		TableReservations: &memoryTableReservationRepository{db: db},
+		Notifications:     &memoryPaymentNotificationRepository{db: db},
+		Categories:        &memoryCategoryRepository{db: db},
+		MenuItems:         &memoryMenuItemRepository{db: db},
+		Refunds:           &memoryRefundRepository{db: db},
+		PaymentEvents:     &memoryPaymentEventRepository{db: db},
+		Outbox:            &memoryOutboxRepository{db: db},
+		IdempotencyKeys:   &memoryIdempotencyKeyRepository{db: db},
+	}
+}
+
+// * PutUser, PutCartItem dan PutMenuItem buat seeding data, soalnya belum ada API buat bikin user/cart
+func (db *MemoryDB) PutUser(user User) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if user.ID == "" {
+		user.ID = newMemoryID()
+	}
+	db.users[user.ID] = user
+}
+
+func (db *MemoryDB) PutCartItem(item CartItem) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if item.ID == "" {
+		item.ID = newMemoryID()
+	}
+	db.cartItems[item.ID] = item
+}
+
+func (db *MemoryDB) PutMenuItem(item DenormalizedMenuItem) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if item.ID == "" {
+		item.ID = newMemoryID()
+	}
+	db.menuItems[item.ID] = item
+}
+
+// * clearCartItemsLocked harus dipanggil sambil pegang db.mu
+func (db *MemoryDB) clearCartItemsLocked(userID string, menuItemIDs []string) {
+	for id, item := range db.cartItems {
+		if item.UserId == userID && slices.Contains(menuItemIDs, item.MenuItemId) {
+			delete(db.cartItems, id)
+		}
+	}
+}
+
+const memoryIDAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// * newMemoryID niru format auto ID Firestore (20 karakter alfanumerik)
+func newMemoryID() string {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	for i := range b {
+		b[i] = memoryIDAlphabet[int(b[i])%len(memoryIDAlphabet)]
+	}
+	return string(b)
+}
+
+// * sortedKeys biar urutan hasil list sama kayak Firestore (urut berdasarkan document ID)
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
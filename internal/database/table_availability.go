@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+)
+
+// * DefaultSlotInterval: jarak antar slot yang ditawarkan ke customer
+const DefaultSlotInterval = 30 * time.Minute
+
+// * OpeningHours disimpan sebagai offset dari jam 00:00 WIB, Close <= Open berarti tutup lewat tengah malam
+type OpeningHours struct {
+	Open  time.Duration
+	Close time.Duration
+}
+
+// * ParseOpeningHours nerima format "10:00-22:00"
+func ParseOpeningHours(value string) (OpeningHours, error) {
+	var openHour, openMinute, closeHour, closeMinute int
+	if _, err := fmt.Sscanf(value, "%d:%d-%d:%d", &openHour, &openMinute, &closeHour, &closeMinute); err != nil {
+		return OpeningHours{}, fmt.Errorf("invalid opening hours %q, must be HH:MM-HH:MM", value)
+	}
+	for _, part := range []struct{ hour, minute int }{{openHour, openMinute}, {closeHour, closeMinute}} {
+		if part.hour < 0 || part.hour > 24 || part.minute < 0 || part.minute > 59 {
+			return OpeningHours{}, fmt.Errorf("invalid opening hours %q, must be HH:MM-HH:MM", value)
+		}
+	}
+
+	hours := OpeningHours{
+		Open:  time.Duration(openHour)*time.Hour + time.Duration(openMinute)*time.Minute,
+		Close: time.Duration(closeHour)*time.Hour + time.Duration(closeMinute)*time.Minute,
+	}
+	if hours.Close <= hours.Open {
+		hours.Close += 24 * time.Hour
+	}
+	return hours, nil
+}
+
+// * Window ngembaliin jam buka dan tutup untuk tanggal (WIB) tertentu
+func (h OpeningHours) Window(date time.Time) (time.Time, time.Time) {
+	year, month, day := date.In(gateway.WIB).Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, gateway.WIB)
+	return midnight.Add(h.Open), midnight.Add(h.Close)
+}
+
+// * Allows true kalau reservasi di jam t selesai sebelum tutup, dicek juga window hari sebelumnya buat jam lewat tengah malam
+func (h OpeningHours) Allows(t time.Time, seating time.Duration) bool {
+	for _, date := range []time.Time{t, t.AddDate(0, 0, -1)} {
+		open, close := h.Window(date)
+		if !t.Before(open) && !t.Add(seating).After(close) {
+			return true
+		}
+	}
+	return false
+}
+
+type TableAvailabilityRequest struct {
+	Date            time.Time // Hari yang dicari, dihitung dalam WIB
+	PartySize       int
+	Location        enums.Location // Kosong = semua lokasi
+	OpeningHours    OpeningHours
+	SeatingDuration time.Duration
+	SlotInterval    time.Duration
+}
+
+type TableAvailability struct {
+	Table RestaurantTable
+	Slots []time.Time // Jam mulai reservasi yang masih kosong
+}
+
+// * GetTableAvailability ngitung slot kosong per meja dari reservasi yang masih aktif dan jam buka.
+// * Slot yang sudah lewat gak ditawarkan. Hasilnya tetap dicek ulang di CreateOrder.
+func GetTableAvailability(ctx context.Context, store *Store, req TableAvailabilityRequest) ([]TableAvailability, error) {
+	if req.SeatingDuration <= 0 {
+		req.SeatingDuration = DefaultSeatingDuration
+	}
+	if req.SlotInterval <= 0 {
+		req.SlotInterval = DefaultSlotInterval
+	}
+
+	tables, err := store.Tables.GetAllRestaurantTables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tables: %v", err)
+	}
+
+	open, close := req.OpeningHours.Window(req.Date)
+	from := open.Add(-req.SeatingDuration)
+	to := close.Add(req.SeatingDuration)
+	reservations, err := store.TableReservations.ListTableReservations(ctx, ListTableReservationsRequest{From: &from, To: &to})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservations: %v", err)
+	}
+
+	reservedTimes := map[string][]time.Time{}
+	for _, reservation := range reservations {
+		if slices.Contains(activeReservationStatuses, reservation.Status) {
+			reservedTimes[reservation.TableID] = append(reservedTimes[reservation.TableID], reservationTimeOf(reservation))
+		}
+	}
+
+	now := time.Now()
+	availability := []TableAvailability{}
+	for _, table := range tables {
+		if !table.IsAvailable || table.Capacity < req.PartySize {
+			continue
+		}
+		if req.Location != "" && table.Location != req.Location {
+			continue
+		}
+
+		slots := []time.Time{}
+		for slot := open; !slot.Add(req.SeatingDuration).After(close); slot = slot.Add(req.SlotInterval) {
+			if slot.Before(now) {
+				continue
+			}
+			free := true
+			for _, reserved := range reservedTimes[table.ID] {
+				if reservationsOverlap(reserved, slot, req.SeatingDuration) {
+					free = false
+					break
+				}
+			}
+			if free {
+				slots = append(slots, slot)
+			}
+		}
+
+		availability = append(availability, TableAvailability{Table: table, Slots: slots})
+	}
+
+	return availability, nil
+}
@@ -1,9 +1,180 @@
 package database
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"time"
 
+	"cloud.google.com/go/firestore"
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+// * Data meja gak diterima dari client, CreateOrder yang load dari koleksi tables
 type CreateTableReservationRequest struct {
 	TableId         string
 	ReservationTime time.Time
-	Table           *RestaurantTable
+	PartySize       int
+}
+
+// * ListTableReservationsRequest: field kosong / nil berarti gak difilter
+type ListTableReservationsRequest struct {
+	UserID  string
+	TableID string
+	Status  enums.ReservationStatus
+	From    *time.Time // Inklusif
+	To      *time.Time // Eksklusif
+}
+
+type firestoreTableReservationRepository struct {
+	client *firestore.Client
+}
+
+func (r *firestoreTableReservationRepository) NewTableReservationID() string {
+	return r.client.Collection("tableReservations").NewDoc().ID
+}
+
+func (r *firestoreTableReservationRepository) GetTableReservationByID(ctx context.Context, id string) (*TableReservation, error) {
+	docRef := r.client.Collection("tableReservations").Doc(id)
+	docSnapshot, err := docRef.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table reservation %s: %w", id, mapFirestoreError(err))
+	}
+
+	var reservation TableReservation
+	if err := docSnapshot.DataTo(&reservation); err != nil {
+		return nil, fmt.Errorf("failed to decode table reservation %s: %v", id, err)
+	}
+
+	return &reservation, nil
+}
+
+func (r *firestoreTableReservationRepository) GetTableReservationByOrderID(ctx context.Context, orderID string) (*TableReservation, error) {
+	docs, err := r.client.Collection("tableReservations").
+		Where("orderId", "==", orderID).
+		Limit(1).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table reservation for order %s: %v", orderID, err)
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no table reservation for order %s: %w", orderID, ErrNotFound)
+	}
+
+	var reservation TableReservation
+	if err := docs[0].DataTo(&reservation); err != nil {
+		return nil, fmt.Errorf("failed to decode table reservation for order %s: %v", orderID, err)
+	}
+
+	return &reservation, nil
+}
+
+func (r *firestoreTableReservationRepository) ListTableReservations(ctx context.Context, request ListTableReservationsRequest) ([]TableReservation, error) {
+	query := r.client.Collection("tableReservations").Query
+	if request.UserID != "" {
+		query = query.Where("userId", "==", request.UserID)
+	}
+	if request.TableID != "" {
+		query = query.Where("tableId", "==", request.TableID)
+	}
+	if request.Status != "" {
+		query = query.Where("status", "==", request.Status)
+	}
+	if request.From != nil {
+		query = query.Where("reservationTime", ">=", *request.From)
+	}
+	if request.To != nil {
+		query = query.Where("reservationTime", "<", *request.To)
+	}
+
+	docs, err := query.OrderBy("reservationTime", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table reservations: %v", err)
+	}
+
+	reservations := make([]TableReservation, 0, len(docs))
+	for _, doc := range docs {
+		var reservation TableReservation
+		if err := doc.DataTo(&reservation); err != nil {
+			return nil, fmt.Errorf("failed to decode table reservation %s: %v", doc.Ref.ID, err)
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, nil
+}
+
+func (r *firestoreTableReservationRepository) UpdateTableReservationStatus(ctx context.Context, id string, status enums.ReservationStatus) (*TableReservation, error) {
+	docRef := r.client.Collection("tableReservations").Doc(id)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docSnapshot, err := tx.Get(docRef)
+		if err != nil {
+			return mapFirestoreError(err)
+		}
+		var current TableReservation
+		if err := docSnapshot.DataTo(&current); err != nil {
+			return fmt.Errorf("failed to decode table reservation %s: %v", id, err)
+		}
+		if !enums.CanTransition(current.Status, status) {
+			return newInvalidTransitionError("status", current.Status, status)
+		}
+
+		current.ID = id
+		change, err := readReservationChangeInTx(r.client, tx, current, status)
+		if err != nil {
+			return err
+		}
+		return change.apply(tx)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update table reservation %s: %w", id, err)
+	}
+	return r.GetTableReservationByID(ctx, id)
+}
+
+// * firestoreReservationChange nyimpen hasil read di dalam transaksi, baru ditulis lewat apply
+// * karena Firestore mewajibkan semua read sebelum write
+type firestoreReservationChange struct {
+	reservationRef *firestore.DocumentRef
+	status         enums.ReservationStatus
+	tableRef       *firestore.DocumentRef // nil kalau IsOccupied gak berubah / mejanya sudah dihapus
+	isOccupied     bool
+}
+
+func readReservationChangeInTx(client *firestore.Client, tx *firestore.Transaction, current TableReservation, status enums.ReservationStatus) (*firestoreReservationChange, error) {
+	change := &firestoreReservationChange{
+		reservationRef: client.Collection("tableReservations").Doc(current.ID),
+		status:         status,
+	}
+
+	// * Status meja ikut diubah di transaksi yang sama, meja yang sudah dihapus dilewati
+	isOccupied, updateTable := tableOccupancyAfter(current.Status, status)
+	if updateTable {
+		table, err := getTableInTx(client, tx, current.TableID)
+		if err != nil {
+			return nil, err
+		}
+		if table != nil {
+			change.tableRef = client.Collection("tables").Doc(current.TableID)
+			change.isOccupied = isOccupied
+		}
+	}
+
+	return change, nil
+}
+
+func (c *firestoreReservationChange) apply(tx *firestore.Transaction) error {
+	if c.tableRef != nil {
+		err := tx.Update(c.tableRef, []firestore.Update{
+			{Path: "isOccupied", Value: c.isOccupied},
+			{Path: "updatedAt", Value: firestore.ServerTimestamp},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Update(c.reservationRef, []firestore.Update{
+		{Path: "status", Value: c.status},
+		{Path: "updatedAt", Value: firestore.ServerTimestamp},
+	})
 }
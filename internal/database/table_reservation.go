@@ -0,0 +1,210 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+// * DefaultSeatingDuration: lama satu reservasi makai meja kalau gak dikonfigurasi
+const DefaultSeatingDuration = 2 * time.Hour
+
+const (
+	alternativeSlotStep        = 30 * time.Minute
+	alternativeSearchWindow    = 2 * time.Hour
+	maxReservationAlternatives = 5
+)
+
+// * ErrReservationConflict dibungkus ReservationConflictError, cek pakai errors.Is / errors.As
+var ErrReservationConflict = errors.New("reservation conflict")
+
+// * Reservasi yang masih makai meja, dipakai buat cek bentrok
+var activeReservationStatuses = []enums.ReservationStatus{enums.StatusReserved, enums.StatusOccupied}
+
+type ReservationAlternative struct {
+	TableID         string
+	TableNumber     string
+	Location        enums.Location
+	Capacity        int
+	ReservationTime time.Time
+}
+
+type ReservationConflictError struct {
+	TableID         string
+	ReservationTime time.Time
+	Reason          string
+	Alternatives    []ReservationAlternative // Diisi CreateOrderWithPayment setelah transaksi gagal
+}
+
+func (e *ReservationConflictError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrReservationConflict, e.Reason)
+}
+
+func (e *ReservationConflictError) Unwrap() error {
+	return ErrReservationConflict
+}
+
+// * reservationTimeOf buat field reservationTime yang tipenya any (time.Time dari Firestore / memory)
+func reservationTimeOf(reservation TableReservation) time.Time {
+	t, _ := reservation.ReservationTime.(time.Time)
+	return t
+}
+
+// * Dua reservasi di meja yang sama bentrok kalau jaraknya kurang dari satu durasi duduk
+func reservationsOverlap(a, b time.Time, seating time.Duration) bool {
+	diff := a.Sub(b)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff < seating
+}
+
+// * tableOccupancyAfter ngembaliin nilai IsOccupied meja setelah reservasi pindah status.
+// * IsAvailable gak pernah disentuh, itu saklar admin. ok false berarti IsOccupied gak perlu diubah.
+func tableOccupancyAfter(from, to enums.ReservationStatus) (isOccupied bool, ok bool) {
+	switch {
+	case to == enums.StatusOccupied:
+		return true, true
+	case from == enums.StatusOccupied:
+		return false, true
+	}
+	return false, false
+}
+
+// * reservationStatusForOrder: status reservasi yang ngikutin status order, ok false kalau reservasi gak perlu diubah
+func reservationStatusForOrder(status enums.OrderStatus) (enums.ReservationStatus, bool) {
+	switch status {
+	case enums.OrderStatusCancelled:
+		return enums.StatusCancelled, true
+	case enums.OrderStatusCompleted:
+		return enums.StatusCompleted, true
+	}
+	return "", false
+}
+
+// * reservationStatusAfterOrder dipanggil UpdateOrder (SyncReservation) di dalam transaksi yang sama:
+// * order cancelled (termasuk pembayaran expire / deny / gagal) bikin reservasi cancelled, order completed
+// * bikin reservasi completed. ok false kalau reservasi dibiarkan, misalnya sudah final karena no-show.
+func reservationStatusAfterOrder(order Order, reservation TableReservation) (enums.ReservationStatus, bool) {
+	status, ok := reservationStatusForOrder(order.Status)
+	if !ok || reservation.Status == status || !enums.CanTransition(reservation.Status, status) {
+		return "", false
+	}
+	return status, true
+}
+
+// * checkNewReservation dipanggil di dalam transaksi CreateOrder dengan data meja dan reservasi terbaru.
+// * table nil berarti mejanya gak ada. Meja yang lagi occupied tetap bisa dipesan buat jam lain,
+// * bentrok cuma ditentukan dari jarak waktu reservasi.
+func checkNewReservation(table *RestaurantTable, reservation TableReservation, seating time.Duration, existing []TableReservation) error {
+	if table == nil {
+		return fmt.Errorf("%w: table %s not found", ErrInvalidOrder, reservation.TableID)
+	}
+
+	at := reservationTimeOf(reservation)
+	conflict := func(reason string) error {
+		return &ReservationConflictError{TableID: table.ID, ReservationTime: at, Reason: reason}
+	}
+
+	if !table.IsAvailable {
+		return conflict(fmt.Sprintf("table %s is not available", table.TableNumber))
+	}
+	if table.Capacity < reservation.PartySize {
+		return conflict(fmt.Sprintf("table %s seats %d, party size is %d", table.TableNumber, table.Capacity, reservation.PartySize))
+	}
+	for _, other := range existing {
+		if other.TableID != table.ID || !slices.Contains(activeReservationStatuses, other.Status) {
+			continue
+		}
+		if reservationsOverlap(reservationTimeOf(other), at, seating) {
+			return conflict(fmt.Sprintf("table %s is already reserved around that time", table.TableNumber))
+		}
+	}
+
+	return nil
+}
+
+// * FindReservationAlternatives nyari meja lain yang kosong di jam yang sama, lalu jam terdekat
+// * (kelipatan 30 menit, maksimal 2 jam) di meja yang diminta atau meja lain yang muat.
+// * Kalau openingHours diisi, jam di luar jam buka dilewati pakai cek yang sama dengan pembuatan order.
+func FindReservationAlternatives(ctx context.Context, store *Store, tableID string, partySize int, at time.Time, seating time.Duration, openingHours *OpeningHours, limit int) ([]ReservationAlternative, error) {
+	tables, err := store.Tables.GetAllRestaurantTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from := at.Add(-seating - alternativeSearchWindow)
+	to := at.Add(seating + alternativeSearchWindow)
+	reservations, err := store.TableReservations.ListTableReservations(ctx, ListTableReservationsRequest{From: &from, To: &to})
+	if err != nil {
+		return nil, err
+	}
+
+	// * Meja yang diminta ditaruh paling depan biar alternatif jamnya diprioritaskan
+	var candidates []RestaurantTable
+	for _, table := range tables {
+		if !table.IsAvailable || table.Capacity < partySize {
+			continue
+		}
+		if table.ID == tableID {
+			candidates = append([]RestaurantTable{table}, candidates...)
+		} else {
+			candidates = append(candidates, table)
+		}
+	}
+
+	isFree := func(table RestaurantTable, t time.Time) bool {
+		if openingHours != nil && !openingHours.Allows(t, seating) {
+			return false
+		}
+		for _, reservation := range reservations {
+			if reservation.TableID == table.ID &&
+				slices.Contains(activeReservationStatuses, reservation.Status) &&
+				reservationsOverlap(reservationTimeOf(reservation), t, seating) {
+				return false
+			}
+		}
+		return true
+	}
+
+	alternatives := []ReservationAlternative{}
+	add := func(table RestaurantTable, t time.Time) bool {
+		alternatives = append(alternatives, ReservationAlternative{
+			TableID:         table.ID,
+			TableNumber:     table.TableNumber,
+			Location:        table.Location,
+			Capacity:        table.Capacity,
+			ReservationTime: t,
+		})
+		return len(alternatives) >= limit
+	}
+
+	for _, table := range candidates {
+		if table.ID != tableID && isFree(table, at) && add(table, at) {
+			return alternatives, nil
+		}
+	}
+
+	now := time.Now()
+	for offset := alternativeSlotStep; offset <= alternativeSearchWindow; offset += alternativeSlotStep {
+		for _, t := range []time.Time{at.Add(-offset), at.Add(offset)} {
+			if t.Before(now) {
+				continue
+			}
+			for _, table := range candidates {
+				if isFree(table, t) {
+					if add(table, t) {
+						return alternatives, nil
+					}
+					break // * Satu meja per jam biar alternatifnya variatif
+				}
+			}
+		}
+	}
+
+	return alternatives, nil
+}
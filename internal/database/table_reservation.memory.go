@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+type memoryTableReservationRepository struct {
+	db *MemoryDB
+}
+
+func (r *memoryTableReservationRepository) NewTableReservationID() string {
+	return newMemoryID()
+}
+
+func (r *memoryTableReservationRepository) GetTableReservationByID(ctx context.Context, id string) (*TableReservation, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	reservation, ok := r.db.tableReservations[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get table reservation %s: %w", id, ErrNotFound)
+	}
+
+	return &reservation, nil
+}
+
+func (r *memoryTableReservationRepository) GetTableReservationByOrderID(ctx context.Context, orderID string) (*TableReservation, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, id := range sortedKeys(r.db.tableReservations) {
+		if reservation := r.db.tableReservations[id]; reservation.OrderID == orderID {
+			return &reservation, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no table reservation for order %s: %w", orderID, ErrNotFound)
+}
+
+func (r *memoryTableReservationRepository) ListTableReservations(ctx context.Context, request ListTableReservationsRequest) ([]TableReservation, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	reservations := []TableReservation{}
+	for _, reservation := range r.db.tableReservations {
+		reservationTime := memoryTime(reservation.ReservationTime)
+		switch {
+		case request.UserID != "" && reservation.UserID != request.UserID,
+			request.TableID != "" && reservation.TableID != request.TableID,
+			request.Status != "" && reservation.Status != request.Status,
+			request.From != nil && reservationTime.Before(*request.From),
+			request.To != nil && !reservationTime.Before(*request.To):
+			continue
+		}
+		reservations = append(reservations, reservation)
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return memoryTime(reservations[i].ReservationTime).Before(memoryTime(reservations[j].ReservationTime))
+	})
+
+	return reservations, nil
+}
+
+func (r *memoryTableReservationRepository) UpdateTableReservationStatus(ctx context.Context, id string, status enums.ReservationStatus) (*TableReservation, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	reservation, ok := r.db.tableReservations[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to update table reservation %s: %w", id, ErrNotFound)
+	}
+	if !enums.CanTransition(reservation.Status, status) {
+		return nil, fmt.Errorf("failed to update table reservation %s: %w", id, newInvalidTransitionError("status", reservation.Status, status))
+	}
+
+	reservation = r.db.setReservationStatusLocked(reservation, status)
+	return &reservation, nil
+}
+
+// * setReservationStatusLocked harus dipanggil sambil pegang db.mu, IsOccupied meja ikut diubah
+func (db *MemoryDB) setReservationStatusLocked(reservation TableReservation, status enums.ReservationStatus) TableReservation {
+	now := time.Now()
+	if isOccupied, ok := tableOccupancyAfter(reservation.Status, status); ok {
+		if table, exists := db.tables[reservation.TableID]; exists {
+			table.IsOccupied = isOccupied
+			table.UpdatedAt = now
+			db.tables[table.ID] = table
+		}
+	}
+
+	reservation.Status = status
+	reservation.UpdatedAt = now
+	db.tableReservations[reservation.ID] = reservation
+	return reservation
+}
+
+// * syncReservationLocked harus dipanggil sambil pegang db.mu, reservasi yang sudah dihapus dilewati
+func (db *MemoryDB) syncReservationLocked(order Order, reservationID string) {
+	reservation, ok := db.tableReservations[reservationID]
+	if !ok {
+		return
+	}
+	if status, ok := reservationStatusAfterOrder(order, reservation); ok {
+		db.setReservationStatusLocked(reservation, status)
+	}
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+)
+
+var (
+	// * ErrReservationOccupied: tamu sudah duduk, cuma admin yang boleh batalin
+	ErrReservationOccupied = errors.New("reservation is already occupied")
+	// * ErrReservationOrderActive: order-nya sudah dibayar dan masih jalan, cancel / refund order dulu
+	ErrReservationOrderActive = errors.New("reservation order is still active")
+)
+
+// * CancelReservation batalin reservasi beserta order-nya yang belum dibayar.
+// * Order belum dibayar dibatalin lewat CancelOrder, reservasinya ikut cancelled di transaksi yang sama.
+// * Order yang sudah dibayar dan masih jalan (atau masih creating) ditolak dengan ErrReservationOrderActive.
+func CancelReservation(ctx context.Context, store *Store, paymentGateway gateway.PaymentGateway, reservationID string, allowOccupied bool) (*TableReservation, error) {
+	reservation, err := store.TableReservations.GetTableReservationByID(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.Status == enums.StatusOccupied && !allowOccupied {
+		return nil, fmt.Errorf("failed to cancel reservation %s: %w", reservationID, ErrReservationOccupied)
+	}
+
+	// * Reservasi yang sudah final cukup diteruskan, UpdateTableReservationStatus yang nolak / no-op
+	if reservation.OrderID != "" && slices.Contains(activeReservationStatuses, reservation.Status) {
+		order, err := store.Orders.GetOrderByID(ctx, reservation.OrderID)
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			return nil, err
+		case order.Status == enums.OrderStatusCancelled || order.Status == enums.OrderStatusCompleted:
+		case order.Status != enums.OrderStatusCreating &&
+			(order.PaymentStatus == enums.PaymentStatusPending || order.PaymentStatus == enums.PaymentStatusChallenge):
+			if _, err := CancelOrder(ctx, store, paymentGateway, order.ID); err != nil {
+				return nil, err
+			}
+			return store.TableReservations.GetTableReservationByID(ctx, reservationID)
+		default:
+			return nil, fmt.Errorf("failed to cancel reservation %s: %w: order %s is %s with payment %s",
+				reservationID, ErrReservationOrderActive, order.ID, order.Status, order.PaymentStatus)
+		}
+	}
+
+	return store.TableReservations.UpdateTableReservationStatus(ctx, reservationID, enums.StatusCancelled)
+}
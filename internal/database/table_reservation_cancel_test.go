@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+func TestCancelReservation(t *testing.T) {
+	tests := []struct {
+		name              string
+		reservationStatus enums.ReservationStatus
+		orderStatus       enums.OrderStatus
+		paymentStatus     enums.PaymentStatus
+		allowOccupied     bool
+		want              error
+		wantOrderStatus   enums.OrderStatus
+	}{
+		{
+			name:              "unpaid order is cancelled too",
+			reservationStatus: enums.StatusReserved,
+			orderStatus:       enums.OrderStatusPending,
+			paymentStatus:     enums.PaymentStatusPending,
+			wantOrderStatus:   enums.OrderStatusCancelled,
+		},
+		{
+			name:              "paid order is still live",
+			reservationStatus: enums.StatusReserved,
+			orderStatus:       enums.OrderStatusConfirmed,
+			paymentStatus:     enums.PaymentStatusSuccess,
+			want:              ErrReservationOrderActive,
+			wantOrderStatus:   enums.OrderStatusConfirmed,
+		},
+		{
+			name:              "order still creating",
+			reservationStatus: enums.StatusReserved,
+			orderStatus:       enums.OrderStatusCreating,
+			paymentStatus:     enums.PaymentStatusPending,
+			want:              ErrReservationOrderActive,
+			wantOrderStatus:   enums.OrderStatusCreating,
+		},
+		{
+			name:              "cancelled order",
+			reservationStatus: enums.StatusReserved,
+			orderStatus:       enums.OrderStatusCancelled,
+			paymentStatus:     enums.PaymentStatusFailure,
+			wantOrderStatus:   enums.OrderStatusCancelled,
+		},
+		{
+			name:              "occupied without admin",
+			reservationStatus: enums.StatusOccupied,
+			orderStatus:       enums.OrderStatusPending,
+			paymentStatus:     enums.PaymentStatusPending,
+			want:              ErrReservationOccupied,
+			wantOrderStatus:   enums.OrderStatusPending,
+		},
+		{
+			name:              "occupied by admin",
+			reservationStatus: enums.StatusOccupied,
+			orderStatus:       enums.OrderStatusPending,
+			paymentStatus:     enums.PaymentStatusPending,
+			allowOccupied:     true,
+			wantOrderStatus:   enums.OrderStatusCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, store, table := newTestOrderDB(t)
+			ctx := context.Background()
+
+			// * Order manual biar CancelOrder gak perlu ke payment gateway
+			request := testCreateOrderRequest("o1", table.ID, time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC))
+			request.Order.Status = tt.orderStatus
+			request.Order.PaymentStatus = tt.paymentStatus
+			request.Order.ManualPayment = true
+			request.TableReservation.Status = tt.reservationStatus
+			if err := store.Orders.CreateOrder(ctx, request); err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+
+			_, err := CancelReservation(ctx, store, nil, "r-o1", tt.allowOccupied)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("CancelReservation error = %v, want %v", err, tt.want)
+			}
+
+			reservation, _ := store.TableReservations.GetTableReservationByID(ctx, "r-o1")
+			if cancelled := reservation.Status == enums.StatusCancelled; cancelled != (tt.want == nil) {
+				t.Errorf("reservation status = %s, cancelled want %v", reservation.Status, tt.want == nil)
+			}
+			order, _ := store.Orders.GetOrderByID(ctx, "o1")
+			if order.Status != tt.wantOrderStatus {
+				t.Errorf("order status = %s, want %s", order.Status, tt.wantOrderStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+// * DefaultNoShowGracePeriod: lama nunggu customer datang setelah jam reservasi sebelum mejanya dilepas
+const DefaultNoShowGracePeriod = 30 * time.Minute
+
+type ReleaseNoShowReservationsResult struct {
+	Checked  int
+	Released int
+	Skipped  int
+	Failed   int
+}
+
+// * ReleaseNoShowReservations nyari reservasi yang masih reserved padahal jamnya sudah lewat lebih dari
+// * gracePeriod, lalu di-cancel biar mejanya bisa dipesan lagi. Order-nya gak diubah.
+func ReleaseNoShowReservations(ctx context.Context, store *Store, now time.Time, gracePeriod time.Duration, batchSize int) (ReleaseNoShowReservationsResult, error) {
+	result := ReleaseNoShowReservationsResult{}
+
+	before := now.Add(-gracePeriod)
+	reservations, err := store.TableReservations.ListTableReservations(ctx, ListTableReservationsRequest{
+		Status: enums.StatusReserved,
+		To:     &before,
+	})
+	if err != nil {
+		return result, err
+	}
+	if len(reservations) > batchSize {
+		reservations = reservations[:batchSize]
+	}
+
+	for _, reservation := range reservations {
+		result.Checked++
+
+		var transitionErr *InvalidTransitionError
+		_, err := store.TableReservations.UpdateTableReservationStatus(ctx, reservation.ID, enums.StatusCancelled)
+		switch {
+		case errors.As(err, &transitionErr):
+			// * Keburu check-in atau order-nya selesai duluan
+			result.Skipped++
+		case err != nil:
+			result.Failed++
+			log.Printf("NO_SHOW: Failed to release reservation %s: %v", reservation.ID, err)
+		default:
+			result.Released++
+			log.Printf("NO_SHOW: Released reservation %s for order %s (reserved at %s)", reservation.ID, reservation.OrderID, reservationTimeOf(reservation).Format(time.RFC3339))
+		}
+	}
+
+	return result, nil
+}
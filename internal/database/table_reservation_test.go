@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
+)
+
+func TestFindReservationAlternativesRespectsOpeningHours(t *testing.T) {
+	_, store, table := newTestOrderDB(t)
+	ctx := context.Background()
+	at := time.Date(2030, 1, 1, 12, 0, 0, 0, gateway.WIB)
+
+	if err := store.Orders.CreateOrder(ctx, testCreateOrderRequest("o1", table.ID, at)); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	openingHours := OpeningHours{Open: 10 * time.Hour, Close: 14 * time.Hour}
+	tests := []struct {
+		name         string
+		openingHours *OpeningHours
+		wantOutside  bool // ada alternatif di luar jam buka
+	}{
+		{name: "without opening hours", wantOutside: true},
+		{name: "with opening hours", openingHours: &openingHours},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alternatives, err := FindReservationAlternatives(ctx, store, table.ID, 2, at, time.Hour, tt.openingHours, 10)
+			if err != nil {
+				t.Fatalf("FindReservationAlternatives: %v", err)
+			}
+			if len(alternatives) == 0 {
+				t.Fatal("got no alternatives")
+			}
+
+			var outside bool
+			for _, alt := range alternatives {
+				if !openingHours.Allows(alt.ReservationTime, time.Hour) {
+					outside = true
+				}
+			}
+			if outside != tt.wantOutside {
+				t.Errorf("alternative outside opening hours = %v, want %v (%+v)", outside, tt.wantOutside, alternatives)
+			}
+		})
+	}
+}
@@ -8,6 +8,10 @@ import (
 	"google.golang.org/api/iterator"
 )
 
+type firestoreUserRepository struct {
+	client *firestore.Client
+}
+
 // type CreateUserRequest struct {
 // 	Name               string
 // 	Description        string
@@ -54,9 +58,9 @@ import (
 // 	return &newUser, nil
 // }
 
-func GetAllUsers(ctx context.Context, client *firestore.Client) ([]User, error) {
+func (r *firestoreUserRepository) GetAllUsers(ctx context.Context) ([]User, error) {
 	var users []User
-	iter := client.Collection("users").Documents(ctx)
+	iter := r.client.Collection("users").Documents(ctx)
 	defer iter.Stop()
 
 	for {
@@ -78,11 +82,11 @@ func GetAllUsers(ctx context.Context, client *firestore.Client) ([]User, error)
 	return users, nil
 }
 
-func GetUserByID(ctx context.Context, client *firestore.Client, id string) (*User, error) {
-	docRef := client.Collection("users").Doc(id)
+func (r *firestoreUserRepository) GetUserByID(ctx context.Context, id string) (*User, error) {
+	docRef := r.client.Collection("users").Doc(id)
 	docSnapshot, err := docRef.Get(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user %s: %v", id, err)
+		return nil, fmt.Errorf("failed to get user %s: %w", id, mapFirestoreError(err))
 	}
 
 	var user User
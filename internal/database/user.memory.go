@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+type memoryUserRepository struct {
+	db *MemoryDB
+}
+
+func (r *memoryUserRepository) GetAllUsers(ctx context.Context) ([]User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var users []User
+	for _, id := range sortedKeys(r.db.users) {
+		users = append(users, r.db.users[id])
+	}
+
+	return users, nil
+}
+
+func (r *memoryUserRepository) GetUserByID(ctx context.Context, id string) (*User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	user, ok := r.db.users[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get user %s: %w", id, ErrNotFound)
+	}
+
+	return &user, nil
+}
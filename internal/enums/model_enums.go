@@ -35,6 +35,8 @@ const (
 type OrderStatus string
 
 const (
+	// * OrderStatusCreating: order sudah disimpan tapi charge ke Midtrans belum selesai
+	OrderStatusCreating  OrderStatus = "creating"
 	OrderStatusPending   OrderStatus = "pending"
 	OrderStatusConfirmed OrderStatus = "confirmed"
 	OrderStatusPreparing OrderStatus = "preparing"
@@ -51,6 +53,9 @@ const (
 	PaymentStatusDeny      PaymentStatus = "deny"
 	PaymentStatusFailure   PaymentStatus = "failure"
 	PaymentStatusPending   PaymentStatus = "pending"
+	// * Refund cuma bisa setelah success
+	PaymentStatusPartialRefund PaymentStatus = "partialRefund"
+	PaymentStatusRefunded      PaymentStatus = "refunded"
 )
 
 type PaymentMethodType string
@@ -0,0 +1,49 @@
+package enums
+
+import "slices"
+
+// * Tabel transisi status. Status yang gak punya entry (completed, cancelled, deny, failure, refunded) itu final.
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusCreating:  {OrderStatusPending, OrderStatusCancelled},
+	OrderStatusPending:   {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed: {OrderStatusPreparing, OrderStatusCancelled},
+	OrderStatusPreparing: {OrderStatusReady, OrderStatusCancelled},
+	OrderStatusReady:     {OrderStatusCompleted},
+}
+
+var paymentStatusTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending:   {PaymentStatusChallenge, PaymentStatusSuccess, PaymentStatusDeny, PaymentStatusFailure},
+	PaymentStatusChallenge: {PaymentStatusSuccess, PaymentStatusDeny, PaymentStatusFailure},
+	PaymentStatusSuccess:   {PaymentStatusPartialRefund, PaymentStatusRefunded},
+	// * Partial refund berkali-kali tetap partialRefund (no-op) sampai sisanya habis
+	PaymentStatusPartialRefund: {PaymentStatusRefunded},
+}
+
+var reservationStatusTransitions = map[ReservationStatus][]ReservationStatus{
+	// * Reserved langsung ke completed kalau order-nya selesai tanpa check-in
+	StatusReserved: {StatusOccupied, StatusCompleted, StatusCancelled},
+	StatusOccupied: {StatusCompleted, StatusCancelled},
+}
+
+type Status interface {
+	OrderStatus | PaymentStatus | ReservationStatus
+}
+
+// * AllowedTransitions ngembaliin status berikutnya yang valid dari `from`
+func AllowedTransitions[S Status](from S) []S {
+	switch from := any(from).(type) {
+	case OrderStatus:
+		return any(slices.Clone(orderStatusTransitions[from])).([]S)
+	case PaymentStatus:
+		return any(slices.Clone(paymentStatusTransitions[from])).([]S)
+	case ReservationStatus:
+		return any(slices.Clone(reservationStatusTransitions[from])).([]S)
+	}
+	return nil
+}
+
+// * CanTransition true kalau `to` boleh dipasang setelah `from`.
+// * Status yang sama dianggap no-op dan selalu boleh.
+func CanTransition[S Status](from, to S) bool {
+	return from == to || slices.Contains(AllowedTransitions(from), to)
+}
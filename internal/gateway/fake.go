@@ -0,0 +1,456 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+)
+
+type fakeTransaction struct {
+	status   coreapi.TransactionStatusResponse
+	amount   int64
+	refunded int64
+}
+
+// * FakeGateway adalah Midtrans palsu yang jalan di dalam proses,
+// * buat test dan local development tanpa akses ke sandbox.
+type FakeGateway struct {
+	ServerKey  string
+	MerchantID string
+	// * NotificationURL tujuan Notify, biasanya http://localhost:PORT/v1/webhooks/midtrans
+	NotificationURL string
+	HTTPClient      *http.Client
+	// * Now bisa diganti di test biar waktu expiry deterministik
+	Now func() time.Time
+
+	mu           sync.Mutex
+	transactions map[string]*fakeTransaction
+}
+
+func NewFakeGateway(serverKey, merchantID, notificationURL string) *FakeGateway {
+	return &FakeGateway{
+		ServerKey:       serverKey,
+		MerchantID:      merchantID,
+		NotificationURL: notificationURL,
+		HTTPClient:      http.DefaultClient,
+		Now:             time.Now,
+		transactions:    map[string]*fakeTransaction{},
+	}
+}
+
+func (g *FakeGateway) ChargeTransaction(req *coreapi.ChargeReq) (*coreapi.ChargeResponse, *midtrans.Error) {
+	if req == nil || req.TransactionDetails.OrderID == "" {
+		return nil, fakeError(http.StatusBadRequest, "transaction_details.order_id is required")
+	}
+	if req.PaymentType == "" {
+		return nil, fakeError(http.StatusBadRequest, "payment_type is required")
+	}
+	if req.TransactionDetails.GrossAmt <= 0 {
+		return nil, fakeError(http.StatusBadRequest, "transaction_details.gross_amount must be greater than 0")
+	}
+	if req.Items != nil {
+		var sum int64
+		for _, item := range *req.Items {
+			sum += item.Price * int64(item.Qty)
+		}
+		if sum != req.TransactionDetails.GrossAmt {
+			return nil, fakeError(http.StatusBadRequest, "transaction_details.gross_amount is not equal to the sum of item_details")
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	orderID := req.TransactionDetails.OrderID
+	if _, exists := g.transactions[orderID]; exists {
+		return nil, fakeError(http.StatusNotAcceptable, "The request could not be completed due to a conflict with the current state of the target resource, please try again")
+	}
+
+	now := g.Now().In(WIB)
+	resp := &coreapi.ChargeResponse{
+		TransactionID:     fakeUUID(),
+		OrderID:           orderID,
+		GrossAmount:       formatGrossAmount(req.TransactionDetails.GrossAmt),
+		Currency:          "IDR",
+		PaymentType:       string(req.PaymentType),
+		TransactionTime:   now.Format(TimeLayout),
+		TransactionStatus: "pending",
+		FraudStatus:       "accept",
+		StatusCode:        "201",
+		StatusMessage:     "Success, transaction is created",
+	}
+
+	var expiry time.Duration
+	switch req.PaymentType {
+	case coreapi.PaymentTypeBankTransfer:
+		expiry = 24 * time.Hour
+		bank := "bca"
+		if req.BankTransfer != nil && req.BankTransfer.Bank != "" {
+			bank = string(req.BankTransfer.Bank)
+		}
+		if bank == string(midtrans.BankPermata) {
+			resp.PermataVaNumber = randomDigits(18)
+		} else {
+			resp.VaNumbers = []coreapi.VANumber{{Bank: bank, VANumber: fakeVANumber(bank)}}
+		}
+	case coreapi.PaymentTypeEChannel:
+		expiry = 24 * time.Hour
+		resp.BillKey = randomDigits(12)
+		resp.BillerCode = "70012"
+	case coreapi.PaymentTypeGopay:
+		expiry = 15 * time.Minute
+		resp.Actions = []coreapi.Action{
+			{Name: "generate-qr-code", Method: http.MethodGet, URL: fmt.Sprintf("https://api.sandbox.midtrans.com/v2/gopay/%s/qr-code", resp.TransactionID)},
+			{Name: "deeplink-redirect", Method: http.MethodGet, URL: fmt.Sprintf("https://simulator.sandbox.midtrans.com/gopay/partner/app/payment-pin?id=%s", resp.TransactionID)},
+			{Name: "get-status", Method: http.MethodGet, URL: fmt.Sprintf("https://api.sandbox.midtrans.com/v2/%s/status", resp.TransactionID)},
+			{Name: "cancel", Method: http.MethodPost, URL: fmt.Sprintf("https://api.sandbox.midtrans.com/v2/%s/cancel", resp.TransactionID)},
+		}
+	case coreapi.PaymentTypeShopeepay:
+		expiry = 5 * time.Minute
+		resp.Actions = []coreapi.Action{
+			{Name: "deeplink-redirect", Method: http.MethodGet, URL: fmt.Sprintf("https://simulator.sandbox.midtrans.com/v2/shopeepay/payment?id=%s", resp.TransactionID)},
+		}
+	case coreapi.PaymentTypeQris:
+		expiry = 15 * time.Minute
+		acquirer := "gopay"
+		if req.Qris != nil && req.Qris.Acquirer != "" {
+			acquirer = req.Qris.Acquirer
+		}
+		resp.Acquirer = acquirer
+		resp.QRString = "00020101021226620014COM.GO-JEK.WWW011993600914" + randomDigits(20) + "5204581253033605802ID5904FAKE6007JAKARTA6304" + randomDigits(4)
+		resp.Actions = []coreapi.Action{
+			{Name: "generate-qr-code", Method: http.MethodGet, URL: fmt.Sprintf("https://api.sandbox.midtrans.com/v2/qris/%s/qr-code", resp.TransactionID)},
+		}
+	case coreapi.PaymentTypeConvenienceStore:
+		expiry = 24 * time.Hour
+		store := "indomaret"
+		if req.ConvStore != nil && req.ConvStore.Store != "" {
+			store = req.ConvStore.Store
+		}
+		resp.Store = store
+		if store == "alfamart" {
+			resp.PaymentCode = randomDigits(16)
+		} else {
+			resp.PaymentCode = randomDigits(14)
+		}
+	case coreapi.PaymentTypeCreditCard:
+		if req.CreditCard == nil || req.CreditCard.TokenID == "" {
+			return nil, fakeError(http.StatusBadRequest, "credit_card.token_id is required")
+		}
+		resp.MaskedCard = "48111111-1114"
+		resp.Bank = req.CreditCard.Bank
+		resp.CardType = "credit"
+		if req.CreditCard.Authentication {
+			resp.RedirectURL = fmt.Sprintf("https://api.sandbox.midtrans.com/v2/token/rba/redirect/%s", resp.TransactionID)
+		} else {
+			resp.TransactionStatus = "capture"
+			resp.StatusCode = "200"
+			resp.StatusMessage = "Success, Credit Card transaction is successful"
+			resp.ApprovalCode = randomDigits(13)
+		}
+	case coreapi.PaymentTypeBCAKlikpay, coreapi.PaymentTypeCimbClicks:
+		expiry = 2 * time.Hour
+		resp.RedirectURL = fmt.Sprintf("https://api.sandbox.midtrans.com/v2/%s/redirect/%s", req.PaymentType, resp.TransactionID)
+	default:
+		return nil, fakeError(http.StatusBadRequest, fmt.Sprintf("payment_type %s is not supported by the fake gateway", req.PaymentType))
+	}
+
+	if expiry > 0 {
+		resp.ExpiryTime = now.Add(expiry).Format(TimeLayout)
+	}
+
+	g.transactions[orderID] = &fakeTransaction{
+		amount: req.TransactionDetails.GrossAmt,
+		status: coreapi.TransactionStatusResponse{
+			TransactionTime:   resp.TransactionTime,
+			GrossAmount:       resp.GrossAmount,
+			Currency:          resp.Currency,
+			OrderID:           orderID,
+			PaymentType:       resp.PaymentType,
+			StatusCode:        resp.StatusCode,
+			TransactionID:     resp.TransactionID,
+			TransactionStatus: resp.TransactionStatus,
+			FraudStatus:       resp.FraudStatus,
+			StatusMessage:     resp.StatusMessage,
+			MerchantID:        g.MerchantID,
+			PermataVaNumber:   resp.PermataVaNumber,
+			VaNumbers:         resp.VaNumbers,
+			PaymentCode:       resp.PaymentCode,
+			Store:             resp.Store,
+			MaskedCard:        resp.MaskedCard,
+			Bank:              resp.Bank,
+			ApprovalCode:      resp.ApprovalCode,
+			CardType:          resp.CardType,
+			BillKey:           resp.BillKey,
+			BillerCode:        resp.BillerCode,
+			Acquirer:          resp.Acquirer,
+			ExpiryTime:        resp.ExpiryTime,
+		},
+	}
+
+	return resp, nil
+}
+
+func (g *FakeGateway) CheckTransaction(orderID string) (*coreapi.TransactionStatusResponse, *midtrans.Error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[orderID]
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "Transaction doesn't exist.")
+	}
+	status := tx.status
+	return &status, nil
+}
+
+func (g *FakeGateway) CancelTransaction(orderID string) (*coreapi.CancelResponse, *midtrans.Error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[orderID]
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "Transaction doesn't exist.")
+	}
+	switch tx.status.TransactionStatus {
+	case "pending", "capture", "authorize":
+	default:
+		return nil, fakeError(http.StatusPreconditionFailed, "Transaction status cannot be updated.")
+	}
+
+	g.setStatusLocked(tx, "cancel", tx.status.FraudStatus)
+	tx.status.StatusMessage = "Success, transaction is canceled"
+	return g.chargeResponseLocked(tx), nil
+}
+
+func (g *FakeGateway) ExpireTransaction(orderID string) (*coreapi.ExpireResponse, *midtrans.Error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[orderID]
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "Transaction doesn't exist.")
+	}
+	if tx.status.TransactionStatus != "pending" {
+		return nil, fakeError(http.StatusPreconditionFailed, "Transaction status cannot be updated.")
+	}
+
+	g.setStatusLocked(tx, "expire", tx.status.FraudStatus)
+	tx.status.StatusMessage = "Success, transaction has expired"
+	return g.chargeResponseLocked(tx), nil
+}
+
+func (g *FakeGateway) RefundTransaction(orderID string, req *coreapi.RefundReq) (*coreapi.RefundResponse, *midtrans.Error) {
+	return g.refund(orderID, req, "Success, refund request is approved")
+}
+
+// * Di fake, direct refund sama aja kayak refund biasa, bedanya cuma langsung diproses di Midtrans asli
+func (g *FakeGateway) DirectRefundTransaction(orderID string, req *coreapi.RefundReq) (*coreapi.RefundResponse, *midtrans.Error) {
+	return g.refund(orderID, req, "Success, refund online request is approved")
+}
+
+func (g *FakeGateway) refund(orderID string, req *coreapi.RefundReq, statusMessage string) (*coreapi.RefundResponse, *midtrans.Error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[orderID]
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "Transaction doesn't exist.")
+	}
+	switch tx.status.TransactionStatus {
+	case "settlement", "capture", "partial_refund":
+	default:
+		return nil, fakeError(http.StatusPreconditionFailed, "Transaction status cannot be updated.")
+	}
+
+	amount := tx.amount - tx.refunded
+	if req != nil && req.Amount > 0 {
+		amount = req.Amount
+	}
+	if amount <= 0 || tx.refunded+amount > tx.amount {
+		return nil, fakeError(http.StatusPreconditionFailed, "Refund amount exceeds the remaining transaction amount.")
+	}
+
+	tx.refunded += amount
+	transactionStatus := "partial_refund"
+	if tx.refunded == tx.amount {
+		transactionStatus = "refund"
+	}
+	g.setStatusLocked(tx, transactionStatus, tx.status.FraudStatus)
+	tx.status.RefundAmount = formatGrossAmount(tx.refunded)
+
+	refundKey := ""
+	reason := ""
+	if req != nil {
+		refundKey = req.RefundKey
+		reason = req.Reason
+	}
+	if refundKey == "" {
+		refundKey = fakeUUID()
+	}
+	chargebackID := rand.IntN(1_000_000)
+	tx.status.Refunds = append(tx.status.Refunds, coreapi.RefundDetails{
+		RefundChargebackID: chargebackID,
+		RefundAmount:       formatGrossAmount(amount),
+		Reason:             reason,
+		RefundKey:          refundKey,
+		CreatedAt:          g.Now().In(WIB).Format(TimeLayout),
+	})
+
+	return &coreapi.RefundResponse{
+		StatusCode:         "200",
+		StatusMessage:      statusMessage,
+		TransactionID:      tx.status.TransactionID,
+		OrderID:            orderID,
+		GrossAmount:        tx.status.GrossAmount,
+		Currency:           tx.status.Currency,
+		MerchantID:         g.MerchantID,
+		PaymentType:        tx.status.PaymentType,
+		TransactionTime:    tx.status.TransactionTime,
+		TransactionStatus:  transactionStatus,
+		SettlementTime:     tx.status.SettlementTime,
+		FraudStatus:        tx.status.FraudStatus,
+		RefundChargebackID: chargebackID,
+		RefundAmount:       formatGrossAmount(amount),
+		RefundKey:          refundKey,
+	}, nil
+}
+
+// * SetTransactionStatus mensimulasikan customer bayar / bank nolak, dll.
+// * Panggil Notify setelahnya buat ngirim webhook-nya.
+func (g *FakeGateway) SetTransactionStatus(orderID, transactionStatus, fraudStatus string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[orderID]
+	if !ok {
+		return fmt.Errorf("fake transaction %s not found", orderID)
+	}
+	g.setStatusLocked(tx, transactionStatus, fraudStatus)
+	return nil
+}
+
+// * Notification bikin body webhook yang sudah ditandatangani sesuai status transaksi sekarang
+func (g *FakeGateway) Notification(orderID string) ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[orderID]
+	if !ok {
+		return nil, fmt.Errorf("fake transaction %s not found", orderID)
+	}
+
+	payload := tx.status
+	payload.SignatureKey = SignatureKey(payload.OrderID, payload.StatusCode, payload.GrossAmount, g.ServerKey)
+	return json.Marshal(payload)
+}
+
+// * Notify POST notifikasi ke NotificationURL, persis kayak Midtrans
+func (g *FakeGateway) Notify(ctx context.Context, orderID string) error {
+	if g.NotificationURL == "" {
+		return fmt.Errorf("fake gateway has no notification URL")
+	}
+
+	body, err := g.Notification(orderID)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.NotificationURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build notification request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification for %s: %v", orderID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification for %s rejected with status %d", orderID, resp.StatusCode)
+	}
+	return nil
+}
+
+func (g *FakeGateway) setStatusLocked(tx *fakeTransaction, transactionStatus, fraudStatus string) {
+	tx.status.TransactionStatus = transactionStatus
+	tx.status.FraudStatus = fraudStatus
+	tx.status.StatusCode = statusCodeFor(transactionStatus)
+	if transactionStatus == "settlement" && tx.status.SettlementTime == "" {
+		tx.status.SettlementTime = g.Now().In(WIB).Format(TimeLayout)
+	}
+}
+
+func (g *FakeGateway) chargeResponseLocked(tx *fakeTransaction) *coreapi.ChargeResponse {
+	return &coreapi.ChargeResponse{
+		TransactionID:     tx.status.TransactionID,
+		OrderID:           tx.status.OrderID,
+		GrossAmount:       tx.status.GrossAmount,
+		Currency:          tx.status.Currency,
+		PaymentType:       tx.status.PaymentType,
+		TransactionTime:   tx.status.TransactionTime,
+		TransactionStatus: tx.status.TransactionStatus,
+		FraudStatus:       tx.status.FraudStatus,
+		StatusCode:        tx.status.StatusCode,
+		StatusMessage:     tx.status.StatusMessage,
+	}
+}
+
+func statusCodeFor(transactionStatus string) string {
+	switch transactionStatus {
+	case "pending":
+		return "201"
+	case "deny", "failure":
+		return "202"
+	case "expire":
+		return "407"
+	default:
+		return "200"
+	}
+}
+
+func fakeError(statusCode int, message string) *midtrans.Error {
+	return &midtrans.Error{Message: message, StatusCode: statusCode}
+}
+
+// * Midtrans selalu ngirim gross_amount dengan 2 angka desimal, ini juga dipakai di signature
+func formatGrossAmount(amount int64) string {
+	return strconv.FormatInt(amount, 10) + ".00"
+}
+
+func fakeVANumber(bank string) string {
+	switch bank {
+	case "bca":
+		return randomDigits(11)
+	case "bni":
+		return "988" + randomDigits(13)
+	case "bri":
+		return randomDigits(18)
+	default:
+		return randomDigits(16)
+	}
+}
+
+func randomDigits(n int) string {
+	var sb strings.Builder
+	for range n {
+		sb.WriteByte(byte('0' + rand.IntN(10)))
+	}
+	return sb.String()
+}
+
+func fakeUUID() string {
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
+		rand.Uint32(), rand.Uint32()&0xffff, rand.Uint32()&0xffff, rand.Uint32()&0xffff, rand.Uint64()&0xffffffffffff)
+}
@@ -0,0 +1,278 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+)
+
+const testServerKey = "SB-Mid-server-test"
+
+// * newTestTransaction bikin transaksi bank transfer pending senilai 10000
+func newTestTransaction(t *testing.T, notificationURL string) *FakeGateway {
+	t.Helper()
+	g := NewFakeGateway(testServerKey, "M123", notificationURL)
+	g.Now = func() time.Time { return time.Date(2030, 1, 1, 12, 0, 0, 0, WIB) }
+
+	resp, err := g.ChargeTransaction(&coreapi.ChargeReq{
+		PaymentType:        coreapi.PaymentTypeBankTransfer,
+		TransactionDetails: midtrans.TransactionDetails{OrderID: "o1", GrossAmt: 10000},
+	})
+	if err != nil {
+		t.Fatalf("ChargeTransaction: %v", err.GetMessage())
+	}
+	if resp.TransactionStatus != "pending" || resp.StatusCode != "201" || resp.GrossAmount != "10000.00" {
+		t.Fatalf("charge = %s / %s / %s, want pending / 201 / 10000.00", resp.TransactionStatus, resp.StatusCode, resp.GrossAmount)
+	}
+	return g
+}
+
+func decodeNotification(t *testing.T, body []byte) coreapi.TransactionStatusResponse {
+	t.Helper()
+	var notification coreapi.TransactionStatusResponse
+	if err := json.Unmarshal(body, &notification); err != nil {
+		t.Fatalf("invalid notification %s: %v", body, err)
+	}
+	return notification
+}
+
+func TestFakeNotificationSignature(t *testing.T) {
+	g := newTestTransaction(t, "")
+	if err := g.SetTransactionStatus("o1", "settlement", "accept"); err != nil {
+		t.Fatalf("SetTransactionStatus: %v", err)
+	}
+
+	body, err := g.Notification("o1")
+	if err != nil {
+		t.Fatalf("Notification: %v", err)
+	}
+	n := decodeNotification(t, body)
+
+	// * Dihitung ulang di sini sesuai dokumentasi Midtrans, bukan lewat SignatureKey
+	sum := sha512.Sum512([]byte(n.OrderID + n.StatusCode + n.GrossAmount + testServerKey))
+	if n.SignatureKey != hex.EncodeToString(sum[:]) {
+		t.Errorf("signature_key = %s, want SHA512(order_id + status_code + gross_amount + server_key)", n.SignatureKey)
+	}
+
+	tests := []struct {
+		name        string
+		grossAmount string
+		serverKey   string
+		want        bool
+	}{
+		{name: "valid", grossAmount: n.GrossAmount, serverKey: testServerKey, want: true},
+		{name: "other server key", grossAmount: n.GrossAmount, serverKey: "SB-Mid-server-other"},
+		{name: "tampered amount", grossAmount: "1.00", serverKey: testServerKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature(n.OrderID, n.StatusCode, tt.grossAmount, tt.serverKey, n.SignatureKey); got != tt.want {
+				t.Errorf("VerifySignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFakeNotify(t *testing.T) {
+	var received []byte
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	g := newTestTransaction(t, server.URL)
+	if err := g.SetTransactionStatus("o1", "settlement", "accept"); err != nil {
+		t.Fatalf("SetTransactionStatus: %v", err)
+	}
+	if err := g.Notify(context.Background(), "o1"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+	n := decodeNotification(t, received)
+	if n.TransactionStatus != "settlement" || n.FraudStatus != "accept" || n.StatusCode != "200" {
+		t.Errorf("notification = %s / %s / %s, want settlement / accept / 200", n.TransactionStatus, n.FraudStatus, n.StatusCode)
+	}
+	if !VerifySignature(n.OrderID, n.StatusCode, n.GrossAmount, testServerKey, n.SignatureKey) {
+		t.Error("posted notification does not pass VerifySignature")
+	}
+}
+
+func TestFakeNotifyErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	g := newTestTransaction(t, server.URL)
+	if err := g.Notify(context.Background(), "o1"); err == nil {
+		t.Error("Notify must fail when the webhook rejects the notification")
+	}
+	if err := g.Notify(context.Background(), "missing"); err == nil {
+		t.Error("Notify must fail for an unknown transaction")
+	}
+
+	g.NotificationURL = ""
+	if err := g.Notify(context.Background(), "o1"); err == nil {
+		t.Error("Notify must fail without a notification URL")
+	}
+}
+
+func TestFakeSetTransactionStatus(t *testing.T) {
+	tests := []struct {
+		transactionStatus string
+		wantStatusCode    string
+		wantSettlement    bool
+	}{
+		{transactionStatus: "settlement", wantStatusCode: "200", wantSettlement: true},
+		{transactionStatus: "capture", wantStatusCode: "200"},
+		{transactionStatus: "pending", wantStatusCode: "201"},
+		{transactionStatus: "deny", wantStatusCode: "202"},
+		{transactionStatus: "failure", wantStatusCode: "202"},
+		{transactionStatus: "expire", wantStatusCode: "407"},
+		{transactionStatus: "cancel", wantStatusCode: "200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transactionStatus, func(t *testing.T) {
+			g := newTestTransaction(t, "")
+			if err := g.SetTransactionStatus("o1", tt.transactionStatus, "accept"); err != nil {
+				t.Fatalf("SetTransactionStatus: %v", err)
+			}
+
+			status, err := g.CheckTransaction("o1")
+			if err != nil {
+				t.Fatalf("CheckTransaction: %v", err.GetMessage())
+			}
+			if status.TransactionStatus != tt.transactionStatus || status.StatusCode != tt.wantStatusCode {
+				t.Errorf("status = %s / %s, want %s / %s", status.TransactionStatus, status.StatusCode, tt.transactionStatus, tt.wantStatusCode)
+			}
+			if (status.SettlementTime != "") != tt.wantSettlement {
+				t.Errorf("settlement_time = %q, set want %v", status.SettlementTime, tt.wantSettlement)
+			}
+		})
+	}
+
+	g := newTestTransaction(t, "")
+	if err := g.SetTransactionStatus("missing", "settlement", "accept"); err == nil {
+		t.Error("SetTransactionStatus must fail for an unknown transaction")
+	}
+	if _, err := g.CheckTransaction("missing"); err == nil || err.GetStatusCode() != http.StatusNotFound {
+		t.Errorf("CheckTransaction unknown = %v, want 404", err)
+	}
+}
+
+func TestFakeCancelAndExpire(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       string
+		cancel     bool // false berarti expire
+		wantStatus string
+		wantCode   int // 0 berarti sukses
+	}{
+		{name: "cancel pending", from: "pending", cancel: true, wantStatus: "cancel"},
+		{name: "cancel capture", from: "capture", cancel: true, wantStatus: "cancel"},
+		{name: "cancel settlement", from: "settlement", cancel: true, wantStatus: "settlement", wantCode: http.StatusPreconditionFailed},
+		{name: "expire pending", from: "pending", wantStatus: "expire"},
+		{name: "expire settlement", from: "settlement", wantStatus: "settlement", wantCode: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestTransaction(t, "")
+			if err := g.SetTransactionStatus("o1", tt.from, "accept"); err != nil {
+				t.Fatalf("SetTransactionStatus: %v", err)
+			}
+
+			var code int
+			if tt.cancel {
+				if _, err := g.CancelTransaction("o1"); err != nil {
+					code = err.GetStatusCode()
+				}
+			} else {
+				if _, err := g.ExpireTransaction("o1"); err != nil {
+					code = err.GetStatusCode()
+				}
+			}
+			if code != tt.wantCode {
+				t.Errorf("status code = %d, want %d", code, tt.wantCode)
+			}
+
+			status, _ := g.CheckTransaction("o1")
+			if status.TransactionStatus != tt.wantStatus {
+				t.Errorf("transaction_status = %s, want %s", status.TransactionStatus, tt.wantStatus)
+			}
+		})
+	}
+
+	g := newTestTransaction(t, "")
+	if _, err := g.CancelTransaction("missing"); err == nil || err.GetStatusCode() != http.StatusNotFound {
+		t.Errorf("CancelTransaction unknown = %v, want 404", err)
+	}
+}
+
+func TestFakeRefund(t *testing.T) {
+	g := newTestTransaction(t, "")
+
+	if _, err := g.RefundTransaction("o1", &coreapi.RefundReq{Amount: 1000}); err == nil || err.GetStatusCode() != http.StatusPreconditionFailed {
+		t.Fatalf("refund of a pending transaction = %v, want 412", err)
+	}
+
+	if err := g.SetTransactionStatus("o1", "settlement", "accept"); err != nil {
+		t.Fatalf("SetTransactionStatus: %v", err)
+	}
+
+	resp, err := g.RefundTransaction("o1", &coreapi.RefundReq{Amount: 4000, RefundKey: "k1", Reason: "salah pesan"})
+	if err != nil {
+		t.Fatalf("RefundTransaction: %v", err.GetMessage())
+	}
+	if resp.TransactionStatus != "partial_refund" || resp.RefundAmount != "4000.00" || resp.RefundKey != "k1" {
+		t.Errorf("refund = %s / %s / %s, want partial_refund / 4000.00 / k1", resp.TransactionStatus, resp.RefundAmount, resp.RefundKey)
+	}
+
+	if _, err := g.RefundTransaction("o1", &coreapi.RefundReq{Amount: 7000}); err == nil || err.GetStatusCode() != http.StatusPreconditionFailed {
+		t.Errorf("refund over the remaining amount = %v, want 412", err)
+	}
+
+	// * Amount kosong berarti refund semua sisa
+	resp, err = g.DirectRefundTransaction("o1", &coreapi.RefundReq{RefundKey: "k2"})
+	if err != nil {
+		t.Fatalf("DirectRefundTransaction: %v", err.GetMessage())
+	}
+	if resp.TransactionStatus != "refund" || resp.RefundAmount != "6000.00" {
+		t.Errorf("refund = %s / %s, want refund / 6000.00", resp.TransactionStatus, resp.RefundAmount)
+	}
+
+	body, notificationErr := g.Notification("o1")
+	if notificationErr != nil {
+		t.Fatalf("Notification: %v", notificationErr)
+	}
+	n := decodeNotification(t, body)
+	if n.TransactionStatus != "refund" || n.StatusCode != "200" || n.RefundAmount != "10000.00" {
+		t.Errorf("notification = %s / %s / %s, want refund / 200 / 10000.00", n.TransactionStatus, n.StatusCode, n.RefundAmount)
+	}
+	if len(n.Refunds) != 2 || n.Refunds[0].RefundKey != "k1" || n.Refunds[1].RefundKey != "k2" {
+		t.Errorf("refunds = %+v, want k1 and k2", n.Refunds)
+	}
+	if !VerifySignature(n.OrderID, n.StatusCode, n.GrossAmount, testServerKey, n.SignatureKey) {
+		t.Error("refund notification does not pass VerifySignature")
+	}
+
+	if _, err := g.RefundTransaction("o1", &coreapi.RefundReq{Amount: 1}); err == nil || err.GetStatusCode() != http.StatusPreconditionFailed {
+		t.Errorf("refund of a fully refunded transaction = %v, want 412", err)
+	}
+}
@@ -0,0 +1,47 @@
+package gateway
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+)
+
+// * Format waktu yang dipakai Midtrans (transaction_time, expiry_time, dll), selalu dalam WIB
+const TimeLayout = "2006-01-02 15:04:05"
+
+var WIB = time.FixedZone("WIB", 7*60*60)
+
+// * PaymentGateway sengaja pakai signature yang sama persis dengan coreapi.Client,
+// * jadi *coreapi.Client langsung memenuhi interface ini tanpa adapter.
+type PaymentGateway interface {
+	ChargeTransaction(req *coreapi.ChargeReq) (*coreapi.ChargeResponse, *midtrans.Error)
+	CheckTransaction(orderID string) (*coreapi.TransactionStatusResponse, *midtrans.Error)
+	CancelTransaction(orderID string) (*coreapi.CancelResponse, *midtrans.Error)
+	ExpireTransaction(orderID string) (*coreapi.ExpireResponse, *midtrans.Error)
+	RefundTransaction(orderID string, req *coreapi.RefundReq) (*coreapi.RefundResponse, *midtrans.Error)
+	DirectRefundTransaction(orderID string, req *coreapi.RefundReq) (*coreapi.RefundResponse, *midtrans.Error)
+}
+
+func NewMidtransGateway(serverKey string, env midtrans.EnvironmentType) PaymentGateway {
+	client := coreapi.Client{}
+	client.New(serverKey, env)
+	return &client
+}
+
+// * SignatureKey = SHA512(order_id + status_code + gross_amount + server_key), sesuai dokumentasi Midtrans
+func SignatureKey(orderID, statusCode, grossAmount, serverKey string) string {
+	str := fmt.Sprintf("%s%s%s%s", orderID, statusCode, grossAmount, serverKey)
+	hasher := sha512.New()
+	hasher.Write([]byte(str))
+	return fmt.Sprintf("%x", hasher.Sum(nil))
+}
+
+// * VerifySignature dipakai webhook buat mastiin notifikasi beneran dikirim Midtrans
+func VerifySignature(orderID, statusCode, grossAmount, serverKey, signature string) bool {
+	expected := SignatureKey(orderID, statusCode, grossAmount, serverKey)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) == 1
+}
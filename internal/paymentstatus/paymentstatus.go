@@ -0,0 +1,119 @@
+package paymentstatus
+
+import "github.com/Rizz404/midtrans-handler/internal/enums"
+
+// * Status transaksi dari Midtrans (transaction_status)
+const (
+	TransactionAuthorize         = "authorize"
+	TransactionCapture           = "capture"
+	TransactionSettlement        = "settlement"
+	TransactionPending           = "pending"
+	TransactionDeny              = "deny"
+	TransactionCancel            = "cancel"
+	TransactionExpire            = "expire"
+	TransactionFailure           = "failure"
+	TransactionRefund            = "refund"
+	TransactionPartialRefund     = "partial_refund"
+	TransactionChargeback        = "chargeback"
+	TransactionPartialChargeback = "partial_chargeback"
+)
+
+// * Status fraud dari Midtrans (fraud_status), kosong buat tipe pembayaran selain kartu
+const (
+	FraudAccept    = "accept"
+	FraudChallenge = "challenge"
+	FraudDeny      = "deny"
+)
+
+type Outcome string
+
+const (
+	// * OutcomeUpdate: PaymentStatus (dan mungkin OrderStatus) harus diterapkan ke order
+	OutcomeUpdate Outcome = "update"
+	// * OutcomeNoChange: status dikenal tapi order gak perlu diubah
+	OutcomeNoChange Outcome = "noChange"
+	// * OutcomeUnknown: kombinasi status yang gak dikenal, sebaiknya di-log
+	OutcomeUnknown Outcome = "unknown"
+)
+
+// * Result hasil mapping. PaymentStatus / OrderStatus nil berarti field itu gak diubah.
+type Result struct {
+	Outcome       Outcome
+	PaymentStatus *enums.PaymentStatus
+	OrderStatus   *enums.OrderStatus
+	Reason        string
+}
+
+// * FromMidtrans nerjemahin transaction_status + fraud_status Midtrans ke status order.
+// * Fungsi ini murni (gak ada I/O) dan dipakai webhook, sweeper dan rekonsiliasi.
+func FromMidtrans(transactionStatus, fraudStatus string) Result {
+	switch transactionStatus {
+	case TransactionAuthorize:
+		// * Pre-authorization kartu, uang baru ditahan dan belum di-capture
+		if fraudStatus == FraudChallenge {
+			return update(enums.PaymentStatusChallenge, nil)
+		}
+		if fraudStatus == FraudDeny {
+			return cancelled(enums.PaymentStatusDeny)
+		}
+		return noChange("card authorized, waiting for capture")
+	case TransactionCapture:
+		switch fraudStatus {
+		case FraudAccept, "":
+			return paid()
+		case FraudChallenge:
+			return update(enums.PaymentStatusChallenge, nil)
+		case FraudDeny:
+			return cancelled(enums.PaymentStatusDeny)
+		}
+		return unknown(transactionStatus, fraudStatus)
+	case TransactionSettlement:
+		return paid()
+	case TransactionPending:
+		orderStatus := enums.OrderStatusPending
+		return update(enums.PaymentStatusPending, &orderStatus)
+	case TransactionDeny:
+		return cancelled(enums.PaymentStatusDeny)
+	case TransactionCancel, TransactionExpire, TransactionFailure:
+		return cancelled(enums.PaymentStatusFailure)
+	case TransactionRefund:
+		// * Status order dibiarkan, refund cuma ngubah status pembayaran
+		return update(enums.PaymentStatusRefunded, nil)
+	case TransactionPartialRefund:
+		return update(enums.PaymentStatusPartialRefund, nil)
+	case TransactionChargeback:
+		// * Chargeback = uang ditarik balik lewat bank customer, buat order efeknya sama dengan refund
+		return update(enums.PaymentStatusRefunded, nil)
+	case TransactionPartialChargeback:
+		return update(enums.PaymentStatusPartialRefund, nil)
+	}
+	return unknown(transactionStatus, fraudStatus)
+}
+
+// * IsRefund: notifikasi refund bawa nominal, jadi harus dicatat sebagai refund, bukan cuma ganti status.
+// * Chargeback gak ada rinciannya, jadi tetap lewat FromMidtrans.
+func IsRefund(transactionStatus string) bool {
+	return transactionStatus == TransactionRefund || transactionStatus == TransactionPartialRefund
+}
+
+func update(paymentStatus enums.PaymentStatus, orderStatus *enums.OrderStatus) Result {
+	return Result{Outcome: OutcomeUpdate, PaymentStatus: &paymentStatus, OrderStatus: orderStatus}
+}
+
+func paid() Result {
+	orderStatus := enums.OrderStatusConfirmed
+	return update(enums.PaymentStatusSuccess, &orderStatus)
+}
+
+func cancelled(paymentStatus enums.PaymentStatus) Result {
+	orderStatus := enums.OrderStatusCancelled
+	return update(paymentStatus, &orderStatus)
+}
+
+func noChange(reason string) Result {
+	return Result{Outcome: OutcomeNoChange, Reason: reason}
+}
+
+func unknown(transactionStatus, fraudStatus string) Result {
+	return Result{Outcome: OutcomeUnknown, Reason: "unknown transaction_status " + transactionStatus + " with fraud_status " + fraudStatus}
+}
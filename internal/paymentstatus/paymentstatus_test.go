@@ -0,0 +1,121 @@
+package paymentstatus
+
+import (
+	"testing"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+// * fraudStatuses semua fraud_status yang mungkin dikirim, termasuk kosong dan yang gak dikenal
+var fraudStatuses = []string{"", FraudAccept, FraudChallenge, FraudDeny, "unknown"}
+
+type expected struct {
+	outcome       Outcome
+	paymentStatus enums.PaymentStatus // "" berarti gak diubah
+	orderStatus   enums.OrderStatus   // "" berarti gak diubah
+}
+
+var (
+	paidResult      = expected{OutcomeUpdate, enums.PaymentStatusSuccess, enums.OrderStatusConfirmed}
+	challengeResult = expected{OutcomeUpdate, enums.PaymentStatusChallenge, ""}
+	denyResult      = expected{OutcomeUpdate, enums.PaymentStatusDeny, enums.OrderStatusCancelled}
+	failureResult   = expected{OutcomeUpdate, enums.PaymentStatusFailure, enums.OrderStatusCancelled}
+	noChangeResult  = expected{OutcomeNoChange, "", ""}
+	unknownResult   = expected{OutcomeUnknown, "", ""}
+)
+
+func TestFromMidtrans(t *testing.T) {
+	tests := []struct {
+		transactionStatus string
+		// * byFraud diisi kalau hasilnya tergantung fraud_status, selain itu pakai all
+		byFraud map[string]expected
+		all     expected
+	}{
+		{
+			transactionStatus: TransactionAuthorize,
+			byFraud: map[string]expected{
+				"":             noChangeResult,
+				FraudAccept:    noChangeResult,
+				FraudChallenge: challengeResult,
+				FraudDeny:      denyResult,
+				"unknown":      noChangeResult,
+			},
+		},
+		{
+			transactionStatus: TransactionCapture,
+			byFraud: map[string]expected{
+				"":             paidResult,
+				FraudAccept:    paidResult,
+				FraudChallenge: challengeResult,
+				FraudDeny:      denyResult,
+				"unknown":      unknownResult,
+			},
+		},
+		{transactionStatus: TransactionSettlement, all: paidResult},
+		{transactionStatus: TransactionPending, all: expected{OutcomeUpdate, enums.PaymentStatusPending, enums.OrderStatusPending}},
+		{transactionStatus: TransactionDeny, all: denyResult},
+		{transactionStatus: TransactionCancel, all: failureResult},
+		{transactionStatus: TransactionExpire, all: failureResult},
+		{transactionStatus: TransactionFailure, all: failureResult},
+		{transactionStatus: TransactionRefund, all: expected{OutcomeUpdate, enums.PaymentStatusRefunded, ""}},
+		{transactionStatus: TransactionPartialRefund, all: expected{OutcomeUpdate, enums.PaymentStatusPartialRefund, ""}},
+		{transactionStatus: TransactionChargeback, all: expected{OutcomeUpdate, enums.PaymentStatusRefunded, ""}},
+		{transactionStatus: TransactionPartialChargeback, all: expected{OutcomeUpdate, enums.PaymentStatusPartialRefund, ""}},
+		{transactionStatus: "", all: unknownResult},
+		{transactionStatus: "settled", all: unknownResult},
+	}
+
+	for _, tt := range tests {
+		for _, fraudStatus := range fraudStatuses {
+			want := tt.all
+			if tt.byFraud != nil {
+				var ok bool
+				if want, ok = tt.byFraud[fraudStatus]; !ok {
+					t.Fatalf("missing expectation for %q with fraud_status %q", tt.transactionStatus, fraudStatus)
+				}
+			}
+
+			t.Run(tt.transactionStatus+"/"+fraudStatus, func(t *testing.T) {
+				got := FromMidtrans(tt.transactionStatus, fraudStatus)
+
+				if got.Outcome != want.outcome {
+					t.Fatalf("Outcome = %q, want %q (reason %q)", got.Outcome, want.outcome, got.Reason)
+				}
+				assertStatus(t, "PaymentStatus", got.PaymentStatus, want.paymentStatus)
+				assertStatus(t, "OrderStatus", got.OrderStatus, want.orderStatus)
+				if got.Outcome != OutcomeUpdate && got.Reason == "" {
+					t.Errorf("Reason is empty for outcome %q", got.Outcome)
+				}
+			})
+		}
+	}
+}
+
+func assertStatus[S enums.OrderStatus | enums.PaymentStatus](t *testing.T, field string, got *S, want S) {
+	t.Helper()
+	switch {
+	case want == "" && got != nil:
+		t.Errorf("%s = %q, want unchanged (nil)", field, *got)
+	case want != "" && got == nil:
+		t.Errorf("%s = nil, want %q", field, want)
+	case got != nil && *got != want:
+		t.Errorf("%s = %q, want %q", field, *got, want)
+	}
+}
+
+func TestIsRefund(t *testing.T) {
+	tests := map[string]bool{
+		TransactionRefund:            true,
+		TransactionPartialRefund:     true,
+		TransactionChargeback:        false,
+		TransactionPartialChargeback: false,
+		TransactionSettlement:        false,
+		"":                           false,
+	}
+
+	for transactionStatus, want := range tests {
+		if got := IsRefund(transactionStatus); got != want {
+			t.Errorf("IsRefund(%q) = %v, want %v", transactionStatus, got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+)
+
+const (
+	expiredOrderSweepBatchSize = 100
+	reconciliationBatchSize    = 100
+	outboxBatchSize            = 50
+	noShowBatchSize            = 100
+	// * Entry outbox baru diproses kalau sudah diem segini lama, biar gak rebutan dengan request yang masih jalan
+	outboxStaleAfter  = 2 * time.Minute
+	outboxMaxAttempts = 5
+)
+
+// * startExpiredOrderSweeper jalanin SweepExpiredOrders tiap interval sampai ctx selesai
+func startExpiredOrderSweeper(ctx context.Context, apiCfg *apiConfig, interval time.Duration, dryRun bool) {
+	log.Printf("Expired order sweeper running every %s (dry-run: %t)", interval, dryRun)
+
+	runPeriodically(ctx, interval, func() {
+		result, err := database.SweepExpiredOrders(ctx, apiCfg.DB, apiCfg.PaymentGateway, time.Now(), expiredOrderSweepBatchSize, dryRun)
+		if err != nil {
+			log.Printf("SWEEPER: Failed to query expired orders: %v", err)
+			return
+		}
+		if result.Checked > 0 {
+			log.Printf("SWEEPER: checked=%d cancelled=%d skipped=%d failed=%d", result.Checked, result.Cancelled, result.Skipped, result.Failed)
+		}
+	})
+}
+
+// * startReconciliationJob nyocokin status pembayaran dengan Midtrans tiap interval, jaga-jaga kalau webhook gagal
+func startReconciliationJob(ctx context.Context, apiCfg *apiConfig, interval time.Duration) {
+	log.Printf("Payment reconciliation running every %s", interval)
+
+	runPeriodically(ctx, interval, func() {
+		report, err := database.ReconcileOrders(ctx, apiCfg.DB, apiCfg.PaymentGateway, reconciliationBatchSize)
+		if err != nil {
+			log.Printf("RECONCILE: Failed to query orders: %v", err)
+			return
+		}
+		for _, mismatch := range report.Mismatches {
+			log.Printf("RECONCILE: Order %s stored=%s gateway=%s (%s) applied=%t %s",
+				mismatch.OrderID, mismatch.StoredPaymentStatus, mismatch.GatewayPaymentStatus, mismatch.GatewayTransactionStatus, mismatch.Applied, mismatch.Error)
+		}
+		if report.Checked > 0 {
+			log.Printf("RECONCILE: checked=%d mismatches=%d applied=%d failed=%d", report.Checked, len(report.Mismatches), report.Applied, report.Failed)
+		}
+	})
+}
+
+// * startOutboxWorker nyelesaiin / ngebatalin order yang macet di status creating
+func startOutboxWorker(ctx context.Context, apiCfg *apiConfig, interval time.Duration) {
+	log.Printf("Order outbox worker running every %s", interval)
+
+	runPeriodically(ctx, interval, func() {
+		result, err := database.ProcessOrderOutbox(ctx, apiCfg.DB, apiCfg.PaymentGateway, time.Now().Add(-outboxStaleAfter), outboxBatchSize, outboxMaxAttempts)
+		if err != nil {
+			log.Printf("OUTBOX: Failed to query outbox: %v", err)
+			return
+		}
+		if result.Checked > 0 {
+			log.Printf("OUTBOX: checked=%d finalized=%d compensated=%d failed=%d", result.Checked, result.Finalized, result.Compensated, result.Failed)
+		}
+	})
+}
+
+// * startNoShowReleaser ngelepas reservasi meja yang customernya gak datang lewat dari grace period
+func startNoShowReleaser(ctx context.Context, apiCfg *apiConfig, interval, gracePeriod time.Duration) {
+	log.Printf("No-show reservation releaser running every %s (grace period: %s)", interval, gracePeriod)
+
+	runPeriodically(ctx, interval, func() {
+		result, err := database.ReleaseNoShowReservations(ctx, apiCfg.DB, time.Now(), gracePeriod, noShowBatchSize)
+		if err != nil {
+			log.Printf("NO_SHOW: Failed to query reservations: %v", err)
+			return
+		}
+		if result.Checked > 0 {
+			log.Printf("NO_SHOW: checked=%d released=%d skipped=%d failed=%d", result.Checked, result.Released, result.Skipped, result.Failed)
+		}
+	})
+}
+
+func runPeriodically(ctx context.Context, interval time.Duration, job func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				job()
+			}
+		}
+	}()
+}
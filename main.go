@@ -3,26 +3,33 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
-	"cloud.google.com/go/firestore"
 	firebase "firebase.google.com/go/v4"
+	"github.com/Rizz404/midtrans-handler/internal/database"
+	"github.com/Rizz404/midtrans-handler/internal/gateway"
 	"github.com/Rizz404/midtrans-handler/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	"github.com/joho/godotenv"
 	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/coreapi"
 	"google.golang.org/api/option"
 )
 
 type apiConfig struct {
-	Firestore         *firestore.Client
-	MidtransCore      *coreapi.Client
-	MidtransServerKey string
+	DB                 *database.Store
+	PaymentGateway     gateway.PaymentGateway
+	MidtransServerKey  string
+	MidtransMerchantID string // * Buat verifikasi merchant_id di webhook, boleh kosong
+	// * Lama satu reservasi makai meja, buat cek bentrok reservasi
+	ReservationSeatingDuration time.Duration
+	OpeningHours               database.OpeningHours // * Jam buka restoran dalam WIB
 }
 
 func main() {
@@ -36,6 +43,182 @@ func main() {
 	// * Database
 	ctx := context.Background()
 
+	// * Firebase app cuma diinisialisasi kalau Firestore atau Firebase Auth dipakai
+	var firebaseApp *firebase.App
+	mustFirebaseApp := func() *firebase.App {
+		if firebaseApp == nil {
+			app, err := newFirebaseApp(ctx)
+			if err != nil {
+				log.Fatal(err)
+			}
+			firebaseApp = app
+		}
+		return firebaseApp
+	}
+
+	// * DB_DRIVER=memory buat local development tanpa Firebase
+	var store *database.Store
+	switch os.Getenv("DB_DRIVER") {
+	case "memory":
+		log.Println("Using in-memory database, data will be lost on restart")
+		store = database.NewMemoryStore()
+	case "", "firestore":
+		firestoreClient, err := mustFirebaseApp().Firestore(ctx)
+		if err != nil {
+			log.Fatalf("error getting Firestore client: %v", err)
+		}
+		defer firestoreClient.Close()
+		store = database.NewFirestoreStore(firestoreClient)
+	default:
+		log.Fatalf("unknown DB_DRIVER %q", os.Getenv("DB_DRIVER"))
+	}
+
+	// * MidtransClient
+	serverKey := os.Getenv("MIDTRANS_SERVER_KEY")
+	if serverKey == "" {
+		log.Fatal("MIDTRANS_SERVER_KEY is not found in env")
+	}
+
+	merchantID := os.Getenv("MIDTRANS_MERCHANT_ID")
+
+	// * PAYMENT_GATEWAY=fake buat local development tanpa sandbox Midtrans
+	var paymentGateway gateway.PaymentGateway
+	switch os.Getenv("PAYMENT_GATEWAY") {
+	case "fake":
+		log.Println("Using fake payment gateway, simulate payments with POST /v1/dev/orders/{orderID}/simulate-payment")
+		paymentGateway = gateway.NewFakeGateway(serverKey, merchantID, "http://localhost"+addr+"/v1/webhooks/midtrans")
+	case "", "midtrans":
+		paymentGateway = gateway.NewMidtransGateway(serverKey, midtrans.Sandbox)
+	default:
+		log.Fatalf("unknown PAYMENT_GATEWAY %q", os.Getenv("PAYMENT_GATEWAY"))
+	}
+
+	// * AUTH_VERIFIER=local buat local development, token HS256 ditandatangani pakai AUTH_LOCAL_SECRET
+	var tokenVerifier middleware.TokenVerifier
+	switch os.Getenv("AUTH_VERIFIER") {
+	case "local":
+		secret := os.Getenv("AUTH_LOCAL_SECRET")
+		if secret == "" {
+			log.Fatal("AUTH_LOCAL_SECRET is not found in env")
+		}
+		log.Println("Using local HS256 token verifier, do not use in production")
+		tokenVerifier = middleware.NewHMACTokenVerifier([]byte(secret))
+	case "", "firebase":
+		authClient, err := mustFirebaseApp().Auth(ctx)
+		if err != nil {
+			log.Fatalf("error getting Firebase Auth client: %v", err)
+		}
+		tokenVerifier = middleware.NewFirebaseTokenVerifier(authClient)
+	default:
+		log.Fatalf("unknown AUTH_VERIFIER %q", os.Getenv("AUTH_VERIFIER"))
+	}
+
+	// * Jam buka restoran (WIB) buat slot reservasi, default 10:00-22:00
+	openingHoursValue := os.Getenv("OPENING_HOURS")
+	if openingHoursValue == "" {
+		openingHoursValue = "10:00-22:00"
+	}
+	openingHours, err := database.ParseOpeningHours(openingHoursValue)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	apiCfg := apiConfig{
+		DB:                         store,
+		PaymentGateway:             paymentGateway,
+		MidtransServerKey:          serverKey,
+		MidtransMerchantID:         merchantID,
+		ReservationSeatingDuration: durationFromEnv("RESERVATION_SEATING_DURATION", database.DefaultSeatingDuration),
+		OpeningHours:               openingHours,
+	}
+
+	// * Sweeper order expired, EXPIRED_ORDER_SWEEP_INTERVAL=0 buat matiin
+	sweepInterval := durationFromEnv("EXPIRED_ORDER_SWEEP_INTERVAL", 5*time.Minute)
+	sweepDryRun, _ := strconv.ParseBool(os.Getenv("EXPIRED_ORDER_SWEEP_DRY_RUN"))
+	if sweepInterval > 0 {
+		startExpiredOrderSweeper(ctx, &apiCfg, sweepInterval, sweepDryRun)
+	}
+
+	// * Rekonsiliasi status pembayaran dengan Midtrans, RECONCILE_INTERVAL=0 buat matiin
+	reconcileInterval := durationFromEnv("RECONCILE_INTERVAL", 15*time.Minute)
+	if reconcileInterval > 0 {
+		startReconciliationJob(ctx, &apiCfg, reconcileInterval)
+	}
+
+	// * Outbox worker buat order yang macet di tengah charge, OUTBOX_INTERVAL=0 buat matiin
+	outboxInterval := durationFromEnv("OUTBOX_INTERVAL", time.Minute)
+	if outboxInterval > 0 {
+		startOutboxWorker(ctx, &apiCfg, outboxInterval)
+	}
+
+	// * Lepas reservasi no-show, NO_SHOW_SWEEP_INTERVAL=0 buat matiin
+	noShowInterval := durationFromEnv("NO_SHOW_SWEEP_INTERVAL", 5*time.Minute)
+	if noShowInterval > 0 {
+		startNoShowReleaser(ctx, &apiCfg, noShowInterval, durationFromEnv("NO_SHOW_GRACE_PERIOD", database.DefaultNoShowGracePeriod))
+	}
+
+	router := chi.NewRouter()
+
+	// * Middleware
+	router.Use(middleware.RequestLoggerMiddleware)
+	router.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   []string{"http://*", "https://*"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowedHeaders:   []string{"*"},
+		ExposedHeaders:   []string{"Link", "Idempotent-Replayed"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}))
+
+	v1Router := chi.NewRouter()
+
+	// * Routes
+	v1Router.Get("/health", handlerHealth)
+	v1Router.Mount("/webhooks", webhookRoutes(&apiCfg))
+	v1Router.Group(func(r chi.Router) {
+		// * Semua route di sini butuh Firebase ID token
+		r.Use(middleware.AuthMiddleware(tokenVerifier, store.Users))
+		r.Mount("/payment-methods", paymentMethodRoutes(&apiCfg))
+		r.Mount("/orders", OrderRoutes(&apiCfg))
+		r.Mount("/tables", tableRoutes(&apiCfg))
+		r.Mount("/reservations", reservationRoutes(&apiCfg))
+		r.Mount("/categories", categoryRoutes(&apiCfg))
+		r.Mount("/menu-items", menuItemRoutes(&apiCfg))
+
+		// * Simulasi pembayaran buat fake gateway, di Midtrans asli customer bayar sendiri
+		if _, ok := paymentGateway.(*gateway.FakeGateway); ok {
+			r.Mount("/dev", devRoutes(&apiCfg))
+		}
+	})
+
+	router.Mount("/v1", v1Router)
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: router,
+	}
+
+	log.Printf("Server running on http://localhost%s", addr)
+	err = server.ListenAndServe()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// * durationFromEnv baca durasi (misal "5m") dari env, fallback kalau kosong
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("invalid %s %q: %v", key, value, err)
+	}
+	return parsed
+}
+
+func newFirebaseApp(ctx context.Context) (*firebase.App, error) {
 	// * Validasi semua environment variable yang diperlukan
 	requiredEnvVars := map[string]string{
 		"FIREBASE_TYPE":                        os.Getenv("FIREBASE_TYPE"),
@@ -53,7 +236,7 @@ func main() {
 	// * Periksa apakah ada environment variable yang kosong
 	for key, value := range requiredEnvVars {
 		if value == "" {
-			log.Fatalf("%s is not found in env", key)
+			return nil, fmt.Errorf("%s is not found in env", key)
 		}
 	}
 
@@ -86,7 +269,7 @@ func main() {
 	// * Ubah map menjadi JSON dalam bentuk byte slice
 	credsJSON, err := json.Marshal(creds)
 	if err != nil {
-		log.Fatalf("failed to marshal credentials to JSON: %v", err)
+		return nil, fmt.Errorf("failed to marshal credentials to JSON: %v", err)
 	}
 
 	// * Buat credential option menggunakan JSON yang sudah kita buat
@@ -100,61 +283,8 @@ func main() {
 	// * Inisialisasi aplikasi Firebase dengan config dan option
 	app, err := firebase.NewApp(ctx, config, opt)
 	if err != nil {
-		log.Fatalf("error initializing app with manual credentials: %v\n", err)
-	}
-
-	firestoreClient, err := app.Firestore(ctx)
-	if err != nil {
-		log.Fatalf("error getting Firestore client: %v\n", err)
-	}
-	defer firestoreClient.Close()
-
-	// * MidtransClient
-	serverKey := os.Getenv("MIDTRANS_SERVER_KEY")
-	if serverKey == "" {
-		log.Fatal("MIDTRANS_SERVER_KEY is not found in env")
-	}
-
-	midtransClient := coreapi.Client{}
-	midtransClient.New(serverKey, midtrans.Sandbox)
-
-	apiCfg := apiConfig{
-		Firestore:         firestoreClient,
-		MidtransCore:      &midtransClient,
-		MidtransServerKey: serverKey,
-	}
-
-	router := chi.NewRouter()
-
-	// * Middleware
-	router.Use(middleware.RequestLoggerMiddleware)
-	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://*", "https://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
-		AllowedHeaders:   []string{"*"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
-
-	v1Router := chi.NewRouter()
-
-	// * Routes
-	v1Router.Get("/health", handlerHealth)
-	v1Router.Mount("/webhooks", webhookRoutes(&apiCfg))
-	v1Router.Mount("/payment-methods", paymentMethodRoutes(&apiCfg))
-	v1Router.Mount("/orders", OrderRoutes(&apiCfg))
-
-	router.Mount("/v1", v1Router)
-
-	server := &http.Server{
-		Addr:    addr,
-		Handler: router,
+		return nil, fmt.Errorf("error initializing app with manual credentials: %v", err)
 	}
 
-	log.Printf("Server running on http://localhost%s", addr)
-	err = server.ListenAndServe()
-	if err != nil {
-		log.Fatal(err)
-	}
+	return app, nil
 }
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func menuItemRoutes(apiCfg *apiConfig) http.Handler {
+	r := chi.NewRouter()
+
+	// * Semua user yang login
+	r.Get("/", apiCfg.handlerGetMenuItems)
+	r.Get("/{menuItemID}", apiCfg.handlerGetMenuItemByID)
+
+	// * Admin only
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireRole(enums.RoleAdmin))
+		r.Post("/", apiCfg.handlerCreateMenuItem)
+		r.Put("/{menuItemID}", apiCfg.handlerUpdateMenuItem)
+		r.Patch("/{menuItemID}/availability", apiCfg.handlerSetMenuItemAvailability)
+		r.Delete("/{menuItemID}", apiCfg.handlerDeleteMenuItem)
+	})
+
+	return r
+}
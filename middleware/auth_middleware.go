@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+)
+
+type contextKey string
+
+const userContextKey contextKey = "user"
+
+// * AuthMiddleware verifikasi header "Authorization: Bearer <Firebase ID token>", load user-nya dari DB,
+// * lalu taruh di context. Handler ambil user-nya lewat UserFromContext.
+func AuthMiddleware(verifier TokenVerifier, users database.UserRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || strings.TrimSpace(idToken) == "" {
+				respondWithError(w, http.StatusUnauthorized, "Missing bearer token")
+				return
+			}
+
+			uid, err := verifier.VerifyIDToken(r.Context(), strings.TrimSpace(idToken))
+			if err != nil {
+				respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
+				return
+			}
+
+			user, err := users.GetUserByID(r.Context(), uid)
+			if errors.Is(err, database.ErrNotFound) {
+				respondWithError(w, http.StatusUnauthorized, "User is not registered")
+				return
+			}
+			if err != nil {
+				log.Printf("AUTH: Failed to load user %s: %v", uid, err)
+				respondWithError(w, http.StatusInternalServerError, "Couldn't load user")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithUser(r.Context(), user)))
+		})
+	}
+}
+
+// * ContextWithUser juga dipakai buat nyiapin context di test tanpa lewat token
+func ContextWithUser(ctx context.Context, user *database.User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+func UserFromContext(ctx context.Context) (*database.User, bool) {
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	return user, ok && user != nil
+}
+
+// * Format body error sama dengan respondWithError di package main
+func respondWithError(w http.ResponseWriter, code int, msg string) {
+	data, _ := json.Marshal(map[string]string{"error": msg})
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+var testSecret = []byte("test-secret")
+
+// * failingUserRepository buat ngetes error DB selain ErrNotFound
+type failingUserRepository struct{}
+
+func (failingUserRepository) GetAllUsers(ctx context.Context) ([]database.User, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func (failingUserRepository) GetUserByID(ctx context.Context, id string) (*database.User, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	db := database.NewMemoryDB()
+	db.PutUser(database.User{ID: "u1", Username: "budi", Role: enums.RoleUser})
+	users := db.Store().Users
+
+	tests := []struct {
+		name          string
+		authorization string
+		users         database.UserRepository
+		wantStatus    int
+		wantError     string
+	}{
+		{
+			name:       "missing header",
+			users:      users,
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "Missing bearer token",
+		},
+		{
+			name:          "not a bearer token",
+			authorization: "Basic dTE6cGFzcw==",
+			users:         users,
+			wantStatus:    http.StatusUnauthorized,
+			wantError:     "Missing bearer token",
+		},
+		{
+			name:          "empty bearer token",
+			authorization: "Bearer   ",
+			users:         users,
+			wantStatus:    http.StatusUnauthorized,
+			wantError:     "Missing bearer token",
+		},
+		{
+			name:          "malformed token",
+			authorization: "Bearer not-a-jwt",
+			users:         users,
+			wantStatus:    http.StatusUnauthorized,
+			wantError:     "Invalid or expired token",
+		},
+		{
+			name:          "wrong signature",
+			authorization: "Bearer " + SignHMACToken([]byte("other-secret"), "u1", time.Hour),
+			users:         users,
+			wantStatus:    http.StatusUnauthorized,
+			wantError:     "Invalid or expired token",
+		},
+		{
+			name:          "expired token",
+			authorization: "Bearer " + SignHMACToken(testSecret, "u1", -time.Minute),
+			users:         users,
+			wantStatus:    http.StatusUnauthorized,
+			wantError:     "Invalid or expired token",
+		},
+		{
+			name:          "unknown user",
+			authorization: "Bearer " + SignHMACToken(testSecret, "nobody", time.Hour),
+			users:         users,
+			wantStatus:    http.StatusUnauthorized,
+			wantError:     "User is not registered",
+		},
+		{
+			name:          "user lookup fails",
+			authorization: "Bearer " + SignHMACToken(testSecret, "u1", time.Hour),
+			users:         failingUserRepository{},
+			wantStatus:    http.StatusInternalServerError,
+			wantError:     "Couldn't load user",
+		},
+		{
+			name:          "valid token",
+			authorization: "Bearer " + SignHMACToken(testSecret, "u1", time.Hour),
+			users:         users,
+			wantStatus:    http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			var gotUser *database.User
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				gotUser, _ = UserFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := AuthMiddleware(NewHMACTokenVerifier(testSecret), tt.users)(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				if gotUser == nil || gotUser.ID != "u1" || gotUser.Role != enums.RoleUser {
+					t.Errorf("user in context = %+v, want u1 user", gotUser)
+				}
+				return
+			}
+
+			if called {
+				t.Error("next handler must not run when authentication fails")
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid error body %q: %v", rec.Body.String(), err)
+			}
+			if body["error"] != tt.wantError {
+				t.Errorf("error = %q, want %q", body["error"], tt.wantError)
+			}
+		})
+	}
+}
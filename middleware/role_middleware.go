@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+// * RequireRole nolak request kalau role user (dari AuthMiddleware) gak ada di roles
+func RequireRole(roles ...enums.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := UserFromContext(r.Context())
+			if !ok {
+				respondWithError(w, http.StatusUnauthorized, "Authentication required")
+				return
+			}
+			if !slices.Contains(roles, user.Role) {
+				RespondForbidden(w, "You don't have permission to perform this action", roles...)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// * RespondForbidden dipakai semua pengecekan akses biar body 403 selalu sama formatnya
+func RespondForbidden(w http.ResponseWriter, msg string, requiredRoles ...enums.Role) {
+	type forbiddenResponse struct {
+		Error         string       `json:"error"`
+		Code          string       `json:"code"`
+		RequiredRoles []enums.Role `json:"requiredRoles,omitempty"`
+	}
+
+	data, _ := json.Marshal(forbiddenResponse{
+		Error:         msg,
+		Code:          "forbidden",
+		RequiredRoles: requiredRoles,
+	})
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	w.Write(data)
+}
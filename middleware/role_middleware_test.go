@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Rizz404/midtrans-handler/internal/database"
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+)
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *database.User // nil berarti request belum lewat AuthMiddleware
+		roles      []enums.Role
+		wantStatus int
+	}{
+		{
+			name:       "admin",
+			user:       &database.User{ID: "a1", Role: enums.RoleAdmin},
+			roles:      []enums.Role{enums.RoleAdmin},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "one of several roles",
+			user:       &database.User{ID: "u1", Role: enums.RoleUser},
+			roles:      []enums.Role{enums.RoleAdmin, enums.RoleUser},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "other role",
+			user:       &database.User{ID: "u1", Role: enums.RoleUser},
+			roles:      []enums.Role{enums.RoleAdmin},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "no user",
+			roles:      []enums.Role{enums.RoleAdmin},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := RequireRole(tt.roles...)(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/orders/reconcile", nil)
+			if tt.user != nil {
+				req = req.WithContext(ContextWithUser(req.Context(), tt.user))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if called != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("next handler called = %v, want %v", called, tt.wantStatus == http.StatusOK)
+			}
+			if tt.wantStatus != http.StatusForbidden {
+				return
+			}
+
+			var body struct {
+				Code          string       `json:"code"`
+				RequiredRoles []enums.Role `json:"requiredRoles"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid error body %q: %v", rec.Body.String(), err)
+			}
+			if body.Code != "forbidden" || len(body.RequiredRoles) != len(tt.roles) || body.RequiredRoles[0] != tt.roles[0] {
+				t.Errorf("body = %+v, want code forbidden and requiredRoles %v", body, tt.roles)
+			}
+		})
+	}
+}
@@ -29,14 +29,15 @@ type Category struct {
 }
 
 type DenormalizedMenuItem struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	Price      float64   `json:"price"`
-	ImageUrl   *string   `json:"imageUrl,omitempty"`
-	CategoryId *string   `json:"categoryId,omitempty"`
-	Category   *Category `json:"category,omitempty"`
-	CreatedAt  any       `json:"createdAt"`
-	UpdatedAt  any       `json:"updatedAt"`
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Price       float64   `json:"price"`
+	ImageUrl    *string   `json:"imageUrl,omitempty"`
+	CategoryId  *string   `json:"categoryId,omitempty"`
+	Category    *Category `json:"category,omitempty"`
+	IsAvailable bool      `json:"isAvailable"`
+	CreatedAt   any       `json:"createdAt"`
+	UpdatedAt   any       `json:"updatedAt"`
 }
 
 type CartItem struct {
@@ -79,15 +80,43 @@ type Order struct {
 	PaymentDisplayURL   *string             `json:"paymentDisplayUrl,omitempty"` // Untuk URL QRIS, dll.
 	PaymentExpiry       *time.Time          `json:"paymentExpiry,omitempty"`     // Waktu kedaluwarsa
 	PaymentDetailsRaw   *map[string]any     `json:"paymentDetailsRaw,omitempty"` // Data mentah dari Midtrans
+	ReservationID       *string             `json:"reservationId,omitempty"`
+	ManualPayment       bool                `json:"manualPayment"`
+	PaymentType         *string             `json:"paymentType,omitempty"`
+	BillKey             *string             `json:"billKey,omitempty"`
+	BillerCode          *string             `json:"billerCode,omitempty"`
+	RefundedAmount      float64             `json:"refundedAmount"`
 	CreatedAt           any                 `json:"createdAt"`
 	UpdatedAt           any                 `json:"updatedAt"`
 }
 
+type Refund struct {
+	ID               string    `json:"id"`
+	OrderID          string    `json:"orderId"`
+	Amount           float64   `json:"amount"`
+	Reason           string    `json:"reason"`
+	ActorID          string    `json:"actorId"`
+	Method           string    `json:"method"`
+	RefundKey        string    `json:"refundKey"`
+	GatewayReference *string   `json:"gatewayReference,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type PaymentEvent struct {
+	ID                string         `json:"id"`
+	OrderID           string         `json:"orderId"`
+	Source            string         `json:"source"`
+	TransactionStatus string         `json:"transactionStatus"`
+	Payload           map[string]any `json:"payload"`
+	CreatedAt         time.Time      `json:"createdAt"`
+}
+
 type RestaurantTable struct {
 	ID          string         `json:"id"`
 	TableNumber string         `json:"tableNumber"`
 	Capacity    int            `json:"capacity"`
-	IsAvailable bool           `json:"isAvailable"`
+	IsAvailable bool           `json:"isAvailable"` // Diatur admin, false berarti meja gak bisa dipesan
+	IsOccupied  bool           `json:"isOccupied"`  // Diatur sistem dari reservasi, true selama ada tamu yang duduk
 	Location    enums.Location `json:"location"`
 	CreatedAt   any            `json:"createdAt"`
 	UpdatedAt   any            `json:"updatedAt"`
@@ -100,6 +129,7 @@ type TableReservation struct {
 	OrderID         string                  `json:"orderId"`
 	ReservationTime any                     `json:"reservationTime"`
 	Status          enums.ReservationStatus `json:"status"`
+	PartySize       int                     `json:"partySize"`
 	Table           *RestaurantTable        `json:"table,omitempty"`
 	CreatedAt       any                     `json:"createdAt"`
 	UpdatedAt       any                     `json:"updatedAt"`
@@ -171,14 +201,15 @@ func dbDenormalizedMenuItemToDenormalizedMenuItem(dbMenuItem database.Denormaliz
 	}
 
 	return DenormalizedMenuItem{
-		ID:         dbMenuItem.ID,
-		Name:       dbMenuItem.Name,
-		Price:      dbMenuItem.Price,
-		ImageUrl:   dbMenuItem.ImageUrl,
-		CategoryId: dbMenuItem.CategoryId,
-		Category:   category,
-		CreatedAt:  dbMenuItem.CreatedAt,
-		UpdatedAt:  dbMenuItem.UpdatedAt,
+		ID:          dbMenuItem.ID,
+		Name:        dbMenuItem.Name,
+		Price:       dbMenuItem.Price,
+		ImageUrl:    dbMenuItem.ImageUrl,
+		CategoryId:  dbMenuItem.CategoryId,
+		Category:    category,
+		IsAvailable: dbMenuItem.IsAvailable,
+		CreatedAt:   dbMenuItem.CreatedAt,
+		UpdatedAt:   dbMenuItem.UpdatedAt,
 	}
 }
 
@@ -238,11 +269,54 @@ func dbOrderToOrder(dbOrder database.Order) Order {
 		PaymentDisplayURL:   dbOrder.PaymentDisplayURL,
 		PaymentExpiry:       dbOrder.PaymentExpiry,
 		PaymentDetailsRaw:   dbOrder.PaymentDetailsRaw,
+		ReservationID:       dbOrder.ReservationID,
+		ManualPayment:       dbOrder.ManualPayment,
+		PaymentType:         dbOrder.PaymentType,
+		BillKey:             dbOrder.BillKey,
+		BillerCode:          dbOrder.BillerCode,
+		RefundedAmount:      dbOrder.RefundedAmount,
 		CreatedAt:           dbOrder.CreatedAt,
 		UpdatedAt:           dbOrder.UpdatedAt,
 	}
 }
 
+func dbRefundToRefund(dbRefund database.Refund) Refund {
+	return Refund{
+		ID:               dbRefund.ID,
+		OrderID:          dbRefund.OrderID,
+		Amount:           dbRefund.Amount,
+		Reason:           dbRefund.Reason,
+		ActorID:          dbRefund.ActorID,
+		Method:           dbRefund.Method,
+		RefundKey:        dbRefund.RefundKey,
+		GatewayReference: dbRefund.GatewayReference,
+		CreatedAt:        dbRefund.CreatedAt,
+	}
+}
+
+func dbRefundsToRefunds(dbRefunds []database.Refund) []Refund {
+	refunds := make([]Refund, len(dbRefunds))
+	for i, dbRefund := range dbRefunds {
+		refunds[i] = dbRefundToRefund(dbRefund)
+	}
+	return refunds
+}
+
+func dbPaymentEventsToPaymentEvents(dbEvents []database.PaymentEvent) []PaymentEvent {
+	events := make([]PaymentEvent, len(dbEvents))
+	for i, dbEvent := range dbEvents {
+		events[i] = PaymentEvent{
+			ID:                dbEvent.ID,
+			OrderID:           dbEvent.OrderID,
+			Source:            dbEvent.Source,
+			TransactionStatus: dbEvent.TransactionStatus,
+			Payload:           dbEvent.Payload,
+			CreatedAt:         dbEvent.CreatedAt,
+		}
+	}
+	return events
+}
+
 func dbOrdersToOrders(dbOrders []database.Order) []Order {
 	orders := make([]Order, len(dbOrders))
 	for i, dbOrder := range dbOrders {
@@ -257,6 +331,7 @@ func dbRestaurantTableToRestaurantTable(dbTable database.RestaurantTable) Restau
 		TableNumber: dbTable.TableNumber,
 		Capacity:    dbTable.Capacity,
 		IsAvailable: dbTable.IsAvailable,
+		IsOccupied:  dbTable.IsOccupied,
 		Location:    dbTable.Location,
 		CreatedAt:   dbTable.CreatedAt,
 		UpdatedAt:   dbTable.UpdatedAt,
@@ -285,6 +360,7 @@ func dbTableReservationToTableReservation(dbReservation database.TableReservatio
 		OrderID:         dbReservation.OrderID,
 		ReservationTime: dbReservation.ReservationTime,
 		Status:          dbReservation.Status,
+		PartySize:       dbReservation.PartySize,
 		Table:           table,
 		CreatedAt:       dbReservation.CreatedAt,
 		UpdatedAt:       dbReservation.UpdatedAt,
@@ -307,8 +383,13 @@ func dbPaymentMethodToPaymentMethod(dbPaymentMethod database.PaymentMethod) Paym
 		Logo:               dbPaymentMethod.Logo,
 		PaymentMethodType:  dbPaymentMethod.PaymentMethodType,
 		MidtransIdentifier: dbPaymentMethod.MidtransIdentifier,
+		MinimumAmount:      dbPaymentMethod.MinimumAmount,
+		MaximumAmount:      dbPaymentMethod.MaximumAmount,
 		CreatedAt:          dbPaymentMethod.CreatedAt,
 		UpdatedAt:          dbPaymentMethod.UpdatedAt,
+
+		AdminPaymentCode:          dbPaymentMethod.AdminPaymentCode,
+		AdminPaymentQrCodePicture: dbPaymentMethod.AdminPaymentQrCodePicture,
 	}
 }
 
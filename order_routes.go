@@ -3,15 +3,37 @@ package main
 import (
 	"net/http"
 
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/middleware"
 	"github.com/go-chi/chi/v5"
 )
 
 func OrderRoutes(apiCfg *apiConfig) http.Handler {
 	r := chi.NewRouter()
 
-	r.Post("/", apiCfg.handlerCreateOrder)
-	r.Get("/{orderID}", apiCfg.handlerGetOrderByID)
-	r.Patch("/{orderID}", apiCfg.handlerUpdateOrder)
+	// * Semua user yang login, list order customer otomatis dibatasi ke order miliknya
+	r.With(apiCfg.idempotencyMiddleware).Post("/", apiCfg.handlerCreateOrder)
+	r.Get("/", apiCfg.handlerGetOrders)
+
+	// * Pemilik order atau admin
+	r.Group(func(r chi.Router) {
+		r.Use(apiCfg.orderAccessMiddleware)
+		r.Get("/{orderID}", apiCfg.handlerGetOrderByID)
+		r.Post("/{orderID}/cancel", apiCfg.handlerCancelOrder)
+		r.Get("/{orderID}/refunds", apiCfg.handlerGetRefunds)
+		r.Post("/{orderID}/payment-proof", apiCfg.handlerUploadPaymentProof)
+	})
+
+	// * Admin only
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireRole(enums.RoleAdmin))
+		r.Patch("/{orderID}", apiCfg.handlerUpdateOrder)
+		r.Post("/reconcile", apiCfg.handlerReconcileOrders)
+		r.Post("/{orderID}/sync", apiCfg.handlerSyncOrder)
+		r.Post("/{orderID}/refunds", apiCfg.handlerCreateRefund)
+		r.Get("/{orderID}/payment-events", apiCfg.handlerGetPaymentEvents)
+		r.Post("/{orderID}/manual-payment", apiCfg.handlerReviewManualPayment)
+	})
 
 	return r
 }
@@ -3,18 +3,26 @@ package main
 import (
 	"net/http"
 
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/middleware"
 	"github.com/go-chi/chi/v5"
 )
 
 func paymentMethodRoutes(apiCfg *apiConfig) http.Handler {
 	r := chi.NewRouter()
 
-	r.Post("/", apiCfg.handlerCreatePaymentMethod)
+	// * Semua user yang login
 	r.Get("/", apiCfg.handlerGetAllPaymentMethods)
-	r.Post("/bulk", apiCfg.handlerBulkCreatePaymentMethods)
 	r.Get("/{paymentMethodID}", apiCfg.handlerGetPaymentMethodByID)
-	r.Put("/{paymentMethodID}", apiCfg.handlerUpdatePaymentMethod)
-	r.Delete("/{paymentMethodID}", apiCfg.handlerDeletePaymentMethod)
+
+	// * Admin only
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireRole(enums.RoleAdmin))
+		r.Post("/", apiCfg.handlerCreatePaymentMethod)
+		r.Post("/bulk", apiCfg.handlerBulkCreatePaymentMethods)
+		r.Put("/{paymentMethodID}", apiCfg.handlerUpdatePaymentMethod)
+		r.Delete("/{paymentMethodID}", apiCfg.handlerDeletePaymentMethod)
+	})
 
 	return r
 }
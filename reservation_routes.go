@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func reservationRoutes(apiCfg *apiConfig) http.Handler {
+	r := chi.NewRouter()
+
+	// * Semua user yang login, list reservasi customer otomatis dibatasi ke miliknya
+	r.Get("/", apiCfg.handlerGetReservations)
+
+	// * Pemilik reservasi atau admin
+	r.Group(func(r chi.Router) {
+		r.Use(apiCfg.reservationAccessMiddleware)
+		r.Get("/{reservationID}", apiCfg.handlerGetReservationByID)
+		r.Post("/{reservationID}/cancel", apiCfg.handlerCancelReservation)
+	})
+
+	// * Admin only (staf restoran)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireRole(enums.RoleAdmin))
+		r.Post("/{reservationID}/check-in", apiCfg.handlerCheckInReservation)
+		r.Post("/{reservationID}/complete", apiCfg.handlerCompleteReservation)
+	})
+
+	return r
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Rizz404/midtrans-handler/internal/enums"
+	"github.com/Rizz404/midtrans-handler/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func tableRoutes(apiCfg *apiConfig) http.Handler {
+	r := chi.NewRouter()
+
+	// * Semua user yang login
+	r.Get("/", apiCfg.handlerGetTables)
+	r.Get("/availability", apiCfg.handlerGetTableAvailability)
+	r.Get("/{tableID}", apiCfg.handlerGetTableByID)
+
+	// * Admin only
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireRole(enums.RoleAdmin))
+		r.Post("/", apiCfg.handlerCreateTable)
+		r.Put("/{tableID}", apiCfg.handlerUpdateTable)
+		r.Delete("/{tableID}", apiCfg.handlerDeleteTable)
+	})
+
+	return r
+}